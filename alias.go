@@ -6,6 +6,7 @@ package flaskgo
 import (
 	"github.com/Chendemo12/flaskgo/internal/app"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/Chendemo12/functools/cronjob"
 )
 
@@ -16,11 +17,45 @@ var (
 	NewFlaskGo        = app.NewFlaskGo
 	APIRouter         = app.APIRouter
 	CombinePath       = app.CombinePath
+
+	APIKeyHeader         = app.APIKeyHeader
+	APIKeyQuery          = app.APIKeyQuery
+	APIKeyCookie         = app.APIKeyCookie
+	HTTPBasic            = app.HTTPBasic
+	HTTPBearer           = app.HTTPBearer
+	OAuth2PasswordBearer = app.OAuth2PasswordBearer
+	JWTBearer            = app.JWTBearer
+	SignJWT              = app.SignJWT
+
+	NewTestClient = app.NewTestClient
+	OpenAPIMain   = app.OpenAPIMain
+
+	RegisterModel = godantic.RegisterModel
+
+	ValidateOpenAPI = openapi.Validate
+	DiffOpenAPI     = openapi.Diff
 )
 
 type Field = godantic.Field
 type BaseModel = godantic.BaseModel
 type BaseModelIface = godantic.Iface
+type QueryModel = godantic.QueryModel
+type HeaderModel = godantic.HeaderModel
+type CookieModel = godantic.CookieModel
+type QueryParameter = godantic.QueryParameter
+type FileModel = godantic.FileModel
+type OpenAPIDiffReport = openapi.DiffReport
+type OpenAPIChange = openapi.Change
+
+const ( // 校验错误信息语言
+	LanguageEN = app.LanguageEN
+	LanguageZH = app.LanguageZH
+)
+
+const ( // openapi 文档版本
+	OpenApiVersion303 = openapi.Version303
+	OpenApiVersion310 = openapi.Version310
+)
 
 //goland:noinspection GoUnusedGlobalVariable
 var ( // types
@@ -82,6 +117,20 @@ type Router = app.Router
 type Response = app.Response
 type ResponseHeader = app.ResponseHeader
 type ValidationError = app.ValidationError
+type Security = app.Security
+type HTTPBasicCredentials = app.HTTPBasicCredentials
+type HTTPAuthorizationCredentials = app.HTTPAuthorizationCredentials
+type OAuth2PasswordRequestForm = app.OAuth2PasswordRequestForm
+type JWTKey = app.JWTKey
+type JWTClaims = app.JWTClaims
+type Dependency = app.Dependency
+type Outcome = app.Outcome
+type HTTPException = app.HTTPException
+type ProblemDetails = app.ProblemDetails
+type ExceptionHandlerFunc = app.ExceptionHandlerFunc
+type TestClient = app.TestClient
+type TestResponse = app.TestResponse
+type TestingT = app.TestingT
 
 type CronJob = cronjob.CronJob
 type Scheduler = cronjob.Scheduler
@@ -99,4 +148,23 @@ var (
 	FileResponse            = app.FileResponse
 	HTMLResponse            = app.HTMLResponse
 	AdvancedResponse        = app.AdvancedResponse
+	ExceptionResponse       = app.ExceptionResponse
+	NewHTTPException        = app.NewHTTPException
+
+	ResponseValidationErrorResponse = app.ResponseValidationErrorResponse // 返回值校验错误
+	UnauthorizedResponse            = app.UnauthorizedResponse            // 认证失败
+	ForbiddenResponse               = app.ForbiddenResponse               // 权限不足
 )
+
+// Depends 创建一个依赖项, 其返回值可通过 DependencyValue 按类型获取
+func Depends[T any](fn func(c *Context) (T, *Response), deps ...*Dependency) *Dependency {
+	return app.Depends(fn, deps...)
+}
+
+// DependsYield 创建一个带有清理函数的依赖项, 清理函数在响应写入之后执行, 即使发生panic也会执行
+func DependsYield[T any](fn func(c *Context) (T, func(outcome *Outcome), *Response), deps ...*Dependency) *Dependency {
+	return app.DependsYield(fn, deps...)
+}
+
+// DependencyValue 按类型获取依赖项的返回值
+func DependencyValue[T any](c *Context) (T, bool) { return app.DependencyValue[T](c) }
@@ -0,0 +1,71 @@
+// flaskgo-openapi-diff 比较两个版本的 openapi 文档, 输出 json 格式的比较结果, 存在不兼容的变更时以状态码 1 退出,
+// 通常与 flaskgo.OpenAPIMain 配合, 在 CI 中比较已提交的文档与当前版本的文档:
+//
+//	go run ./cmd/openapi -o /tmp/openapi.yaml
+//	go run ./cmd/flaskgo-openapi-diff docs/openapi.yaml /tmp/openapi.yaml
+//
+// 文档可以是 json 或 yaml 格式, 参数错误或文档无法解析时以状态码 2 退出
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Chendemo12/flaskgo"
+	"os"
+)
+
+var (
+	output   = flag.String("o", "", "比较结果的输出文件, 缺省输出到标准输出")
+	failOn   = flag.Bool("fail", true, "存在不兼容的变更时以状态码 1 退出")
+	breaking = flag.Bool("breaking", false, "仅输出不兼容的变更")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: flaskgo-openapi-diff [flags] <old> <new>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := diff(flag.Arg(0), flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *breaking {
+		report.Changes = report.BreakingChanges()
+	}
+
+	bs, _ := json.MarshalIndent(report, "", "  ")
+	bs = append(bs, '\n')
+	if *output == "" {
+		_, err = os.Stdout.Write(bs)
+	} else {
+		err = os.WriteFile(*output, bs, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *failOn && report.Breaking {
+		os.Exit(1)
+	}
+}
+
+func diff(older, newer string) (*flaskgo.OpenAPIDiffReport, error) {
+	oldDoc, err := os.ReadFile(older)
+	if err != nil {
+		return nil, err
+	}
+	newDoc, err := os.ReadFile(newer)
+	if err != nil {
+		return nil, err
+	}
+	return flaskgo.DiffOpenAPI(oldDoc, newDoc)
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+)
+
+// ErrDependencyCycle 依赖项直接或间接地依赖了自身
+var ErrDependencyCycle = errors.New("dependency cycle detected")
+
+// Dependency 依赖项, 通过 Depends 创建，用于在执行路由函数前计算一个值, 如：数据库连接、当前用户等
+// 依赖项可以依赖其他依赖项，形成依赖图；在同一个请求内，每一个依赖项仅执行一次，其返回值会被缓存并保存于 Context，
+// 路由函数和其他依赖项可通过 DependencyValue 按类型获取
+type Dependency struct {
+	call  func(c *Context) (any, func(outcome *Outcome), *Response)
+	deps  []*Dependency // 子依赖项, 先于此依赖项执行
+	rtype reflect.Type  // 返回值类型
+	Name  string        // 依赖项名称, 缺省为函数名
+}
+
+// Depends 创建一个依赖项
+//
+//	@param	fn		func(c *Context) (T, *Response)	依赖项函数, 若返回的 *Response 不为nil，则终止请求并以此作为响应
+//	@param	deps	[]*Dependency					此依赖项所依赖的其他依赖项, 先于 fn 执行
+//	@return	*Dependency 依赖项
+//
+//	var CurrentUser = flaskgo.Depends(func(c *flaskgo.Context) (*User, *flaskgo.Response) {
+//		db, _ := flaskgo.DependencyValue[*sql.DB](c)
+//		...
+//	}, Database)
+func Depends[T any](fn func(c *Context) (T, *Response), deps ...*Dependency) *Dependency {
+	return &Dependency{
+		call: func(c *Context) (any, func(outcome *Outcome), *Response) {
+			v, resp := fn(c)
+			return v, nil, resp
+		},
+		deps:  deps,
+		rtype: reflect.TypeOf((*T)(nil)).Elem(),
+		Name:  runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name(),
+	}
+}
+
+// DependsYield 创建一个带有清理函数的依赖项, 适用于需要释放资源的依赖项, 如：数据库事务、设备锁等
+// 清理函数在响应写入之后按依赖项执行的相反顺序执行, 即使路由函数返回错误或发生panic也会执行,
+// 清理函数可依据请求的处理结果 Outcome 决定提交或回滚
+//
+//	@param	fn		func(c *Context) (T, func(outcome *Outcome), *Response)	依赖项函数, 返回值, 清理函数(允许为nil)和错误响应
+//	@param	deps	[]*Dependency											此依赖项所依赖的其他依赖项, 先于 fn 执行
+//	@return	*Dependency 依赖项
+//
+//	var Tx = flaskgo.DependsYield(func(c *flaskgo.Context) (*sql.Tx, func(*flaskgo.Outcome), *flaskgo.Response) {
+//		tx, _ := db.Begin()
+//		return tx, func(o *flaskgo.Outcome) {
+//			if o.Failed() {
+//				tx.Rollback()
+//			} else {
+//				tx.Commit()
+//			}
+//		}, nil
+//	})
+func DependsYield[T any](fn func(c *Context) (T, func(outcome *Outcome), *Response), deps ...*Dependency) *Dependency {
+	return &Dependency{
+		call: func(c *Context) (any, func(outcome *Outcome), *Response) {
+			return fn(c)
+		},
+		deps:  deps,
+		rtype: reflect.TypeOf((*T)(nil)).Elem(),
+		Name:  runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name(),
+	}
+}
+
+// Outcome 请求的处理结果, 作为依赖项清理函数的参数
+type Outcome struct {
+	Response *Response // 路由函数或依赖项的返回值, 路由函数无返回值或发生panic时为nil
+	Err      error     // 写入响应时发生的错误
+	Panic    any       // 路由函数或依赖项发生的panic
+}
+
+// StatusCode 响应状态码, 发生panic时为500
+func (o *Outcome) StatusCode() int {
+	if o.Panic != nil {
+		return http.StatusInternalServerError
+	}
+	if o.Response == nil {
+		return http.StatusOK
+	}
+	return o.Response.StatusCode
+}
+
+// Failed 请求是否处理失败, 即发生了panic, 写入响应错误或响应状态码 >= 400
+func (o *Outcome) Failed() bool {
+	return o.Panic != nil || o.Err != nil || o.StatusCode() >= http.StatusBadRequest
+}
+
+// Type 依赖项返回值的类型
+func (d *Dependency) Type() reflect.Type { return d.rtype }
+
+// Value 获取依赖项在当前请求中的返回值
+//
+//	@param	c	*Context	请求上下文
+//	@return	any 返回值
+//	@return	bool 依赖项是否已执行
+func (d *Dependency) Value(c *Context) (any, bool) {
+	v, ok := c.dependencies[d]
+	return v, ok
+}
+
+// DependencyValue 按类型获取依赖项的返回值, 若存在多个返回值类型相同的依赖项, 则返回最后执行的依赖项的返回值
+//
+//	@param	c	*Context	请求上下文
+//	@return	T 依赖项返回值
+//	@return	bool 是否存在此类型的依赖项
+func DependencyValue[T any](c *Context) (T, bool) {
+	var zero T
+	v, ok := c.dependencyTypes[reflect.TypeOf((*T)(nil)).Elem()]
+	if !ok {
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
+// resolveDependency 计算依赖项, 子依赖项先于父依赖项执行，同一个请求内依赖项仅执行一次
+// 若依赖项被 FlaskGo.OverrideDependency 覆盖，则执行替换依赖项，但其返回值仍以原依赖项及其返回值类型为键缓存;
+// 若替换依赖项依赖了原依赖项, 则执行原依赖项本身, 替换依赖项可借此包装原依赖项的返回值,
+// 除此之外的循环依赖返回 ErrDependencyCycle 错误
+func (c *Context) resolveDependency(d *Dependency) *Response {
+	if _, ok := c.dependencies[d]; ok { // 已执行
+		return nil
+	}
+
+	actual := c.app.dependency(d)
+	if running, ok := c.resolving[d]; ok {
+		if running == d {
+			return ExceptionResponse(fmt.Errorf("%w: %s", ErrDependencyCycle, d.Name))
+		}
+		actual = d
+	}
+	if c.resolving == nil {
+		c.resolving = make(map[*Dependency]*Dependency)
+	}
+	prev, hasPrev := c.resolving[d]
+	c.resolving[d] = actual
+	defer func() {
+		if hasPrev {
+			c.resolving[d] = prev
+		} else {
+			delete(c.resolving, d)
+		}
+	}()
+
+	for _, sub := range actual.deps {
+		if resp := c.resolveDependency(sub); resp != nil {
+			return resp
+		}
+	}
+
+	v, cleanup, resp := actual.call(c)
+	if cleanup != nil { // 即使依赖项返回了错误响应，其清理函数仍需执行
+		c.cleanups = append(c.cleanups, cleanup)
+	}
+	if resp != nil {
+		return resp
+	}
+	c.dependencies[d] = v
+	c.dependencyTypes[d.rtype] = v
+
+	return nil
+}
+
+// teardownDependencies 按依赖项执行的相反顺序执行清理函数, 清理函数发生的panic会被记录而不会中断其他清理函数
+func (c *Context) teardownDependencies(outcome *Outcome) {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					c.Logger().Error("dependency teardown panic: ", r)
+				}
+			}()
+			c.cleanups[i](outcome)
+		}()
+	}
+	c.cleanups = nil
+}
+
+// prependDependencies 将 deps 添加到 origin 之前, 并过滤重复的依赖项
+func prependDependencies(deps, origin []*Dependency) []*Dependency {
+	m := make([]*Dependency, 0, len(deps)+len(origin))
+	for _, d := range append(append([]*Dependency{}, deps...), origin...) {
+		found := false
+		for _, e := range m {
+			if e == d {
+				found = true
+				break
+			}
+		}
+		if !found && d != nil {
+			m = append(m, d)
+		}
+	}
+	return m
+}
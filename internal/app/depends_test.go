@@ -0,0 +1,256 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+)
+
+type testGreeter interface{ Greet() string }
+
+type testGreeting string
+
+func (g testGreeting) Greet() string { return string(g) }
+
+// testTokens 不可比较的认证依赖项
+type testTokens []string
+
+func (t testTokens) SchemeName() string { return "Tokens" }
+
+func (t testTokens) Scheme() *openapi.SecurityScheme {
+	return &openapi.SecurityScheme{Type: openapi.SecurityTypeAPIKey, Name: "X-Token", In: openapi.APIKeyInHeader}
+}
+
+func (t testTokens) Authenticate(c *Context) *Response {
+	for _, token := range t {
+		if c.ec.Get("X-Token") == token {
+			return nil
+		}
+	}
+	return UnauthorizedResponse("", NotAuthenticated)
+}
+
+func newOverrideApp(deps ...*Dependency) *FlaskGo {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	router.GET("/greet", godantic.String, "问候", func(c *Context) *Response {
+		if g, ok := DependencyValue[testGreeter](c); ok {
+			return c.StringResponse(g.Greet())
+		}
+		name, _ := DependencyValue[string](c)
+		return c.StringResponse(name)
+	}).Depends(deps...)
+	app.IncludeRouter(router)
+	return app
+}
+
+func TestOverrideDependency(t *testing.T) {
+	name := Depends(func(c *Context) (string, *Response) { return "lee", nil })
+	greeting := Depends(func(c *Context) (string, *Response) {
+		v, _ := name.Value(c)
+		return "hello " + v.(string), nil
+	}, name)
+
+	app := newOverrideApp(greeting)
+	client := NewTestClient(app)
+	client.Get("/api/greet", nil).AssertStatus(t, http.StatusOK).assertText(t, "hello lee")
+
+	// 覆盖子依赖项
+	app.OverrideDependency(name, Depends(func(c *Context) (string, *Response) { return "tom", nil }))
+	client.Get("/api/greet", nil).AssertStatus(t, http.StatusOK).assertText(t, "hello tom")
+
+	// 替换依赖项依赖原依赖项, 原依赖项本身仍会执行
+	app.OverrideDependency(greeting, Depends(func(c *Context) (string, *Response) {
+		v, _ := greeting.Value(c)
+		return strings.ToUpper(v.(string)), nil
+	}, greeting))
+	client.Get("/api/greet", nil).AssertStatus(t, http.StatusOK).assertText(t, "HELLO TOM")
+
+	app.ResetOverrides()
+	client.Get("/api/greet", nil).AssertStatus(t, http.StatusOK).assertText(t, "hello lee")
+}
+
+func TestOverrideDependencyCycle(t *testing.T) {
+	var a, b *Dependency
+	a = Depends(func(c *Context) (string, *Response) { return "a", nil })
+	b = Depends(func(c *Context) (string, *Response) { return "b", nil }, a)
+
+	app := newOverrideApp(b)
+	// a 被替换为依赖 b 的依赖项, 而 b 依赖 a
+	app.OverrideDependency(a, Depends(func(c *Context) (string, *Response) { return "c", nil }, b))
+	NewTestClient(app).Get("/api/greet", nil).AssertStatus(t, http.StatusInternalServerError)
+}
+
+func TestOverrideDependencyType(t *testing.T) {
+	greeter := Depends(func(c *Context) (testGreeter, *Response) { return testGreeting("hi"), nil })
+	app := newOverrideApp(greeter)
+
+	// 实现了原返回值接口的替换依赖项
+	app.OverrideDependency(greeter, Depends(func(c *Context) (testGreeting, *Response) { return "hey", nil }))
+	NewTestClient(app).Get("/api/greet", nil).AssertStatus(t, http.StatusOK).assertText(t, "hey")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a replacement of a different type")
+		}
+	}()
+	app.OverrideDependency(greeter, Depends(func(c *Context) (int, *Response) { return 1, nil }))
+}
+
+func TestOverrideSecurity(t *testing.T) {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+		return c.StringResponse("ok")
+	}).AddSecurity(testTokens{"secret"})
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	req := func(token string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, "/api/me", nil)
+		r.Header.Set("X-Token", token)
+		return r
+	}
+
+	client.Do(req("secret")).AssertStatus(t, http.StatusOK)
+	client.Do(req("other")).AssertStatus(t, http.StatusUnauthorized)
+
+	// 不可比较的认证依赖项同样可以覆盖
+	app.OverrideSecurity(testTokens{"secret"}, testTokens{"other"})
+	client.Do(req("other")).AssertStatus(t, http.StatusOK)
+	client.Do(req("secret")).AssertStatus(t, http.StatusUnauthorized)
+
+	app.OverrideSecurity(testTokens{}, nil)
+	client.Do(req("secret")).AssertStatus(t, http.StatusOK)
+}
+
+func (r *TestResponse) assertText(t *testing.T, text string) {
+	t.Helper()
+	if r.Text() != text {
+		t.Errorf("expected body %q, got %q", text, r.Text())
+	}
+}
+
+func TestDependencyTeardownOutcome(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		status  int
+		panic   bool
+		failed  bool
+	}{
+		{name: "ok", handler: func(c *Context) *Response { return c.StringResponse("ok") }, status: http.StatusOK},
+		{
+			name: "error response", status: http.StatusNotFound, failed: true,
+			handler: func(c *Context) *Response {
+				return c.ExceptionResponse(NewHTTPException(http.StatusNotFound, "not found"))
+			},
+		},
+		{
+			name: "exception panic", status: http.StatusForbidden, failed: true,
+			handler: func(c *Context) *Response { panic(NewHTTPException(http.StatusForbidden, "forbidden")) },
+		},
+		{
+			name: "panic", status: http.StatusInternalServerError, panic: true, failed: true,
+			handler: func(c *Context) *Response { panic("boom") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := make([]string, 0)
+			var outcome *Outcome
+			yield := func(name string) *Dependency {
+				return DependsYield(func(c *Context) (string, func(*Outcome), *Response) {
+					order = append(order, "setup "+name)
+					return name, func(o *Outcome) {
+						order = append(order, "teardown "+name)
+						outcome = o
+						if name == "b" { // 清理函数发生的panic不会中断其他清理函数
+							panic("teardown " + name)
+						}
+					}, nil
+				})
+			}
+			a := yield("a")
+			b := yield("b")
+
+			app := NewFlaskGo("test", "1.0.0", false, nil)
+			router := APIRouter("/api", nil)
+			router.GET("/tx", godantic.String, "事务", tt.handler).Depends(a, b)
+			app.IncludeRouter(router)
+			NewTestClient(app).Get("/api/tx", nil).AssertStatus(t, tt.status)
+
+			want := []string{"setup a", "setup b", "teardown b", "teardown a"}
+			if strings.Join(order, ",") != strings.Join(want, ",") {
+				t.Errorf("expected %v, got %v", want, order)
+			}
+			if outcome == nil {
+				t.Fatal("expected the teardown to receive an outcome")
+			}
+			if (outcome.Panic != nil) != tt.panic || outcome.Failed() != tt.failed {
+				t.Errorf("expected panic=%v failed=%v, got %+v", tt.panic, tt.failed, outcome)
+			}
+			if !tt.panic && outcome.StatusCode() != tt.status {
+				t.Errorf("expected outcome status %d, got %d", tt.status, outcome.StatusCode())
+			}
+		})
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	calls := map[string]int{}
+	db := Depends(func(c *Context) (int, *Response) {
+		calls["db"]++
+		return 42, nil
+	})
+	user := Depends(func(c *Context) (string, *Response) {
+		calls["user"]++
+		if v, _ := DependencyValue[int](c); v != 42 {
+			return "", c.ErrorResponse("db dependency not resolved")
+		}
+		return "lee", nil
+	}, db)
+	auth := Depends(func(c *Context) (bool, *Response) {
+		calls["auth"]++
+		if c.ec.Get("X-Token") == "" {
+			return false, UnauthorizedResponse("", NotAuthenticated)
+		}
+		return true, nil
+	})
+
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil).Depends(auth)
+	// db 同时被路由组和 user 依赖, 同一个请求内仅执行一次
+	router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+		name, _ := DependencyValue[string](c)
+		return c.StringResponse(name)
+	}, db, user)
+	sub := APIRouter("/admin", nil)
+	sub.GET("/ping", godantic.String, "心跳", func(c *Context) *Response {
+		if ok, _ := DependencyValue[bool](c); !ok {
+			return c.ErrorResponse("auth dependency not resolved")
+		}
+		return c.StringResponse("pong")
+	})
+	router.IncludeRouter(sub) // 路由组依赖项同样作用于挂载的子路由组
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	client.Get("/api/me", nil).AssertStatus(t, http.StatusUnauthorized)
+	client.Get("/api/admin/ping", nil).AssertStatus(t, http.StatusUnauthorized)
+	if calls["db"] != 0 || calls["user"] != 0 {
+		t.Errorf("expected the route dependencies to be skipped after the router dependency failed, got %v", calls)
+	}
+
+	client.SetHeader("X-Token", "secret")
+	client.Get("/api/me", nil).AssertStatus(t, http.StatusOK).assertText(t, "lee")
+	client.Get("/api/me", nil).AssertStatus(t, http.StatusOK)
+	client.Get("/api/admin/ping", nil).AssertStatus(t, http.StatusOK).assertText(t, "pong")
+	if calls["db"] != 2 || calls["user"] != 2 || calls["auth"] != 5 {
+		t.Errorf("expected each dependency to run once per request, got %v", calls)
+	}
+}
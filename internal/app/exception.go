@@ -0,0 +1,139 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"reflect"
+)
+
+// HTTPException HTTP异常, 路由函数和依赖项可通过 ExceptionResponse 返回或直接 panic 此异常,
+// 其会被转换为状态码为 StatusCode, 响应体为 {"detail": Detail} 的响应
+//
+//	return c.ExceptionResponse(flaskgo.NewHTTPException(http.StatusNotFound, "user not found"))
+//	panic(flaskgo.NewHTTPException(http.StatusConflict, "user already exists"))
+type HTTPException struct {
+	Detail     any               // 错误详情, 需可json序列化
+	Headers    map[string]string // 附加的响应头
+	StatusCode int               // 响应状态码
+}
+
+// NewHTTPException 创建一个HTTP异常
+//
+//	@param	statusCode	int	响应状态码
+//	@param	detail		any	错误详情, 为nil时以状态码的标准描述代替
+//	@return	*HTTPException HTTP异常
+func NewHTTPException(statusCode int, detail any) *HTTPException {
+	if detail == nil {
+		detail = http.StatusText(statusCode)
+	}
+	return &HTTPException{StatusCode: statusCode, Detail: detail}
+}
+
+func (e *HTTPException) Error() string { return fmt.Sprintf("%d: %v", e.StatusCode, e.Detail) }
+
+// SetHeader 设置附加的响应头
+func (e *HTTPException) SetHeader(key, value string) *HTTPException {
+	if e.Headers == nil {
+		e.Headers = map[string]string{}
+	}
+	e.Headers[key] = value
+	return e
+}
+
+// ExceptionHandlerFunc 异常处理函数, 将错误转换为响应
+// 其中 err 为 errors.As 匹配到的注册类型的错误, 可直接进行类型断言
+type ExceptionHandlerFunc = func(c *Context, err error) *Response
+
+// exceptionHandler 一个注册的异常处理函数
+type exceptionHandler struct {
+	rtype reflect.Type
+	fn    ExceptionHandlerFunc
+}
+
+// ExceptionResponse 以错误作为返回值, 此错误会交由 FlaskGo.AddExceptionHandler 注册的异常处理函数处理,
+// *HTTPException 和 *fiber.Error 会以其状态码响应, 其余未注册的错误以500响应
+//
+//	@param	err	error	错误
+//	@return	resp *Response response返回体
+func ExceptionResponse(err error) *Response {
+	return &Response{StatusCode: exceptionStatusCode(err), Content: err, Type: ExceptionResponseType}
+}
+
+// exceptionStatusCode 错误对应的响应状态码, 用于记录请求的处理结果 Outcome
+func exceptionStatusCode(err error) int {
+	var he *HTTPException
+	if errors.As(err, &he) {
+		return he.StatusCode
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// matchException 查找能够处理此错误的异常处理函数, 先注册的优先匹配
+func (f *FlaskGo) matchException(err error) (ExceptionHandlerFunc, error) {
+	for _, h := range f.exceptionHandlers {
+		target := reflect.New(h.rtype)
+		if errors.As(err, target.Interface()) {
+			return h.fn, target.Elem().Interface().(error)
+		}
+	}
+	return nil, nil
+}
+
+// isException 错误是否是可处理的异常, 即 *HTTPException 或已注册异常处理函数的错误
+func (f *FlaskGo) isException(err error) bool {
+	var he *HTTPException
+	if errors.As(err, &he) {
+		return true
+	}
+	fn, _ := f.matchException(err)
+	return fn != nil
+}
+
+// handleException 将错误转换为响应, 依次匹配注册的异常处理函数, *HTTPException 和 *fiber.Error
+func (f *FlaskGo) handleException(ctx *Context, err error) *Response {
+	if fn, matched := f.matchException(err); fn != nil {
+		if resp := fn(ctx, matched); resp != nil {
+			return resp
+		}
+	}
+
+	var he *HTTPException
+	if errors.As(err, &he) {
+		return AdvancedResponse(he.StatusCode, func(c *fiber.Ctx) error {
+			for k, v := range he.Headers {
+				c.Set(k, v)
+			}
+			return writeErrorDetail(c, he.StatusCode, he.Detail)
+		})
+	}
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return &Response{StatusCode: fe.Code, Content: fiber.Map{"detail": fe.Message}, Type: ErrResponseType}
+	}
+
+	f.service.Logger().Error(fmt.Sprintf("unhandled error during '%s: %s', %v", ctx.ec.Method(), ctx.ec.Path(), err))
+	return &Response{
+		StatusCode: http.StatusInternalServerError,
+		Content:    fiber.Map{"detail": http.StatusText(http.StatusInternalServerError)},
+		Type:       ErrResponseType,
+	}
+}
+
+// customFiberErrorHandler 自定义fiber接口错误处理函数, 将路由函数返回或panic的错误交由异常处理函数处理
+func (f *FlaskGo) customFiberErrorHandler(c *fiber.Ctx, e error) error {
+	ctx := f.AcquireCtx(c)
+	defer f.ReleaseCtx(ctx)
+
+	resp := f.handleException(ctx, e)
+	if resp.Type == ExceptionResponseType { // 避免异常处理函数返回异常而导致的循环
+		return writeErrorDetail(c, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return responseWriter(ctx, resp)
+}
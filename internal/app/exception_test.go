@@ -0,0 +1,80 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+type testNotFoundError struct{ Name string }
+
+func (e *testNotFoundError) Error() string { return e.Name + " not found" }
+
+type testConflictError string
+
+func (e testConflictError) Error() string { return string(e) }
+
+func TestExceptionHandler(t *testing.T) {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	app.AddExceptionHandler(&testNotFoundError{}, func(c *Context, err error) *Response {
+		return c.JSONResponse(http.StatusNotFound, map[string]string{"detail": err.(*testNotFoundError).Name})
+	})
+	app.AddExceptionHandler(testConflictError(""), func(c *Context, err error) *Response {
+		if err.(testConflictError) == "ignored" {
+			return nil // 继续使用默认的处理方式
+		}
+		return c.JSONResponse(http.StatusConflict, map[string]string{"detail": err.Error()})
+	})
+
+	errs := map[string]error{
+		"not-found":    &testNotFoundError{Name: "lee"},
+		"wrapped":      fmt.Errorf("query user: %w", &testNotFoundError{Name: "tom"}),
+		"conflict":     testConflictError("user exists"),
+		"ignored":      testConflictError("ignored"),
+		"exception":    NewHTTPException(http.StatusTeapot, nil).SetHeader("X-Reason", "tea"),
+		"unregistered": errors.New("boom"),
+	}
+	router := APIRouter("/api", nil)
+	router.GET("/error/:kind", godantic.String, "返回错误", func(c *Context) *Response {
+		return c.ExceptionResponse(errs[c.PathFields["kind"]])
+	})
+	router.GET("/panic/:kind", godantic.String, "panic错误", func(c *Context) *Response {
+		panic(errs[c.PathFields["kind"]])
+	})
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	tests := []struct {
+		kind   string
+		status int
+		detail string
+	}{
+		{kind: "not-found", status: http.StatusNotFound, detail: "lee"},
+		{kind: "wrapped", status: http.StatusNotFound, detail: "tom"}, // errors.As 匹配包装后的错误
+		{kind: "conflict", status: http.StatusConflict, detail: "user exists"},
+		{kind: "ignored", status: http.StatusInternalServerError, detail: http.StatusText(http.StatusInternalServerError)},
+		{kind: "exception", status: http.StatusTeapot, detail: http.StatusText(http.StatusTeapot)},
+		{kind: "unregistered", status: http.StatusInternalServerError, detail: http.StatusText(http.StatusInternalServerError)},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/api/error/", "/api/panic/"} {
+			t.Run(path[len("/api/"):]+tt.kind, func(t *testing.T) {
+				resp := client.Get(path+tt.kind, nil).AssertStatus(t, tt.status)
+				if tt.kind == "unregistered" && path == "/api/panic/" { // 未注册的错误交由 recover 处理
+					return
+				}
+				body := map[string]string{}
+				if err := resp.JSON(&body); err != nil || body["detail"] != tt.detail {
+					t.Errorf("expected detail %q, got %s", tt.detail, resp.Body)
+				}
+				if tt.kind == "exception" && resp.Header.Get("X-Reason") != "tea" {
+					t.Errorf("expected the exception headers, got %v", resp.Header)
+				}
+			})
+		}
+	}
+}
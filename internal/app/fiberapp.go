@@ -2,7 +2,6 @@ package app
 
 import (
 	"fmt"
-	"github.com/Chendemo12/flaskgo/internal/core"
 	"github.com/Chendemo12/functools/cprint"
 	"github.com/Chendemo12/functools/helper"
 	"github.com/gofiber/fiber/v2"
@@ -11,27 +10,37 @@ import (
 	"runtime"
 )
 
+// appLocalsKey fiber.Ctx 中保存 FlaskGo 实例的键
+const appLocalsKey = "flaskgo.app"
+
 // createFiberApp 创建 fiber.App 已做了基本的中间件配置
-func createFiberApp(title, version string) *fiber.App {
-	if fiberErrorHandler == nil {
-		fiberErrorHandler = customFiberErrorHandler
+func (f *FlaskGo) createFiberApp() *fiber.App {
+	if f.errorHandler == nil {
+		f.errorHandler = f.customFiberErrorHandler
 	}
 
-	if recoverHandler == nil {
-		recoverHandler = customRecoverHandler
+	if f.recoverHandler == nil {
+		f.recoverHandler = f.customRecoverHandler
 	}
+	title, version := f.title, f.version
 
 	// 创建App实例
 	app := fiber.New(fiber.Config{
-		Prefork:       !core.MultipleProcessDisabled, // 多进程模式
-		CaseSensitive: true,                          // 区分路由大小写
-		StrictRouting: true,                          // 严格路由
-		ServerHeader:  title,                         // 服务器头
-		AppName:       title + " v" + version,        // 设置为 Response.Header.Server 属性
-		ColorScheme:   fiber.DefaultColors,           // 彩色输出
-		JSONEncoder:   helper.DefaultJsonMarshal,     // json序列化器
-		JSONDecoder:   helper.DefaultJsonUnmarshal,   // json解码器
-		ErrorHandler:  fiberErrorHandler,             // 设置自定义错误处理
+		Prefork:       !f.flags.MultipleProcessDisabled, // 多进程模式
+		CaseSensitive: true,                             // 区分路由大小写
+		StrictRouting: true,                             // 严格路由
+		ServerHeader:  title,                            // 服务器头
+		AppName:       title + " v" + version,           // 设置为 Response.Header.Server 属性
+		ColorScheme:   fiber.DefaultColors,              // 彩色输出
+		JSONEncoder:   helper.DefaultJsonMarshal,        // json序列化器
+		JSONDecoder:   helper.DefaultJsonUnmarshal,      // json解码器
+		ErrorHandler:  f.errorHandler,                   // 设置自定义错误处理
+	})
+
+	// 记录请求所属的应用, 用于路由查找和 Context 申请
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(appLocalsKey, f)
+		return c.Next()
 	})
 
 	// 输出API访问日志
@@ -45,14 +54,14 @@ func createFiberApp(title, version string) *fiber.App {
 		EnableStackTrace: true,
 		// StackTraceHandler: 处理堆栈跟踪的函数, 若留空，则默认将整个错误堆栈输出到控制台,
 		// 并在处理完成后将错误流转到 fiber.ErrorHandler
-		StackTraceHandler: recoverHandler,
+		StackTraceHandler: f.recoverHandler,
 	}))
 
 	// 设置自定义响应头
 	app.Use(func(c *fiber.Ctx) error {
-		for i := 0; i < len(responseHeaders); i++ {
-			if responseHeaders[i].Value != "" {
-				c.Append(responseHeaders[i].Key, responseHeaders[i].Value)
+		for i := 0; i < len(f.responseHeaders); i++ {
+			if f.responseHeaders[i].Value != "" {
+				c.Append(f.responseHeaders[i].Key, f.responseHeaders[i].Value)
 			}
 		}
 
@@ -63,25 +72,18 @@ func createFiberApp(title, version string) *fiber.App {
 }
 
 // customRecoverHandler 自定义 recover 错误处理函数
-func customRecoverHandler(c *fiber.Ctx, e any) {
+func (f *FlaskGo) customRecoverHandler(c *fiber.Ctx, e any) {
 	buf := make([]byte, 1024)
 	buf = buf[:runtime.Stack(buf, true)]
 	msg := helper.CombineStrings(
 		"Request RelativePath: ", c.Path(), fmt.Sprintf(", Error: %v, \n", e), string(buf),
 	)
 	cprint.Red(msg)
-	appEngine.Service().Logger().Error(msg)
+	f.Service().Logger().Error(msg)
 }
 
-// customFiberErrorHandler 自定义fiber接口错误处理函数
-func customFiberErrorHandler(c *fiber.Ctx, e error) error {
-	//appEngine.logger.Warn(helper.CombineStrings(
-	//	"error happened during: '",
-	//	c.Method(), ": ", c.RelativePath(),
-	//	"', Msg: ", e.Error(),
-	//))
-	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		"code": fiber.StatusBadRequest,
-		"msg":  e.Error()},
-	)
+// appFromCtx 获取请求所属的应用
+func appFromCtx(c *fiber.Ctx) *FlaskGo {
+	f, _ := c.Locals(appLocalsKey).(*FlaskGo)
+	return f
 }
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"github.com/Chendemo12/flaskgo/internal/core"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/Chendemo12/functools/cronjob"
 	"github.com/Chendemo12/functools/logger"
 	"github.com/Chendemo12/functools/python"
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"log"
 	"net"
@@ -25,11 +25,6 @@ const (
 	shutdownEvent EventKind = "shutdown"
 )
 
-var (
-	once               = sync.Once{}
-	appEngine *FlaskGo = nil // 单例模式
-)
-
 type EventKind string
 
 type Event struct {
@@ -53,6 +48,20 @@ type FlaskGo struct {
 	routers     []*Router          `description:"FlaskGo 路由组 Router"`
 	events      []*Event           `description:"启动和关闭事件"`
 	middlewares []any              `description:"自定义中间件"`
+	flags       *core.Flags        `description:"内部标志量"`
+	// 自定义路由, 键为 请求方法+RouteSeparator+路由, 用于其后的请求和响应校验
+	routes          map[string]*Route
+	responseHeaders []*ResponseHeader     `description:"自定义响应头"`
+	errorHandler    fiber.ErrorHandler    `description:"fiber自定义错误处理函数"`
+	recoverHandler  StackTraceHandlerFunc `description:"错误堆栈处理函数"`
+	// 异常处理函数, 按注册顺序匹配
+	exceptionHandlers []*exceptionHandler
+	// 依赖项覆盖, 用于测试时替换依赖项
+	overrideLock        sync.RWMutex
+	dependencyOverrides map[*Dependency]*Dependency
+	securityOverrides   map[string]Security // 以认证方案名称为键
+	// 文档页面的路由及其静态文件地址
+	docs *openapi.DocsConfig
 }
 
 func (f *FlaskGo) isFieldsOk() *FlaskGo {
@@ -81,26 +90,35 @@ func (f *FlaskGo) isFieldsOk() *FlaskGo {
 
 // mountBaseRoutes 创建基础路由
 func (f *FlaskGo) mountBaseRoutes() {
-	// 注册最基础的路由
+	f.routers = append(f.routers, f.baseRouter())
+}
+
+// baseRouter 最基础的路由
+func (f *FlaskGo) baseRouter() *Router {
 	router := APIRouter("/api/base", []string{"Base"})
 	{
 		router.GET("/title", godantic.String, "获取软件名", func(c *Context) *Response {
-			return c.StringResponse(appEngine.title)
+			return c.StringResponse(f.title)
 		})
 		router.GET("/description", godantic.String, "获取软件描述信息", func(c *Context) *Response {
-			return c.StringResponse(appEngine.Description())
+			return c.StringResponse(f.Description())
 		})
 		router.GET("/version", godantic.String, "获取软件版本号", func(c *Context) *Response {
-			return c.StringResponse(appEngine.version)
+			return c.StringResponse(f.version)
 		})
 		router.GET("/heartbeat", godantic.String, "心跳检测", func(c *Context) *Response {
 			return c.StringResponse("pong")
 		})
 		router.GET("/debug", godantic.Bool, "获取调试开关", func(c *Context) *Response {
-			return c.OKResponse(core.IsDebug())
+			return c.OKResponse(f.IsDebug())
 		})
 	}
-	f.routers = append(f.routers, router)
+	return router
+}
+
+// baseRoutesEnabled 是否挂载基础路由, 调试模式下始终挂载
+func (f *FlaskGo) baseRoutesEnabled() bool {
+	return python.Any(f.IsDebug(), !f.flags.BaseRoutesDisabled)
 }
 
 // mountUserRoutes 挂载并记录自定义路由
@@ -111,33 +129,42 @@ func (f *FlaskGo) mountUserRoutes() {
 			switch route.Method {
 			case http.MethodGet:
 				rtr.Get(route.RelativePath, route.Handlers...)
-				// 记录自定义路由
-				MethodGetRoutes[route.Path(router.Prefix)] = route
-
 			case http.MethodPost:
 				rtr.Post(route.RelativePath, route.Handlers...)
-				MethodPostRoutes[route.Path(router.Prefix)] = route
-
 			case http.MethodDelete:
 				rtr.Delete(route.RelativePath, route.Handlers...)
-				MethodDeleteRoutes[route.Path(router.Prefix)] = route
-
 			case http.MethodPatch:
 				rtr.Patch(route.RelativePath, route.Handlers...)
-				MethodPatchRoutes[route.Path(router.Prefix)] = route
-
 			case http.MethodPut:
 				rtr.Put(route.RelativePath, route.Handlers...)
-				MethodPutRoutes[route.Path(router.Prefix)] = route
-
 			case "ANY", "ALL":
 				rtr.All(route.RelativePath, route.Handlers...)
-				MethodGetRoutes[route.Path(router.Prefix)] = route
+			default:
+				continue
 			}
+			// 记录自定义路由
+			f.addRoute(route.Method, route.Path(router.Prefix), route)
 		}
 	}
 }
 
+// addRoute 记录自定义路由, "ANY" 和 "ALL" 路由按 GET 方法记录
+func (f *FlaskGo) addRoute(method, path string, route *Route) {
+	if method == "ANY" || method == "ALL" {
+		method = http.MethodGet
+	}
+	f.routes[method+RouteSeparator+path] = route
+}
+
+// GetRoute 查询自定义路由
+//
+//	@param	method	string	请求方法
+//	@param	path	string	请求路由
+//	@return	*Route 自定义路由对象
+func (f *FlaskGo) GetRoute(method string, path string) *Route {
+	return f.routes[method+RouteSeparator+path]
+}
+
 // initialize 初始化FlaskGo,并完成服务依赖的建立
 // FlaskGo启动前，必须显式的初始化FlaskGo的基本配置，若初始化中发生异常则panic
 //  1. 记录工作地址： host:Port
@@ -147,17 +174,17 @@ func (f *FlaskGo) mountUserRoutes() {
 //  5. 挂载自定义路由 mountUserRoutes
 //  6. 安装创建swagger文档 makeSwaggerDocs
 func (f *FlaskGo) initialize() *FlaskGo {
-	f.service.Logger().Debug("Run at: " + core.GetMode(true))
+	f.service.Logger().Debug("Run at: " + f.flags.GetMode(true))
 
 	// 创建 fiber.App
-	f.engine = createFiberApp(f.title, f.version)
+	f.engine = f.createFiberApp()
 	// 注册中间件
 	for _, middleware := range f.middlewares {
 		f.engine.Use(middleware)
 	}
 
 	// 挂载基础路由
-	if python.Any(core.IsDebug(), !core.BaseRoutesDisabled) {
+	if f.baseRoutesEnabled() {
 		f.mountBaseRoutes()
 	}
 	// 挂载自定义路由
@@ -194,7 +221,7 @@ func (f *FlaskGo) Title() string   { return f.title }
 func (f *FlaskGo) Host() string    { return f.host }
 func (f *FlaskGo) Port() string    { return f.port }
 func (f *FlaskGo) Version() string { return f.version }
-func (f *FlaskGo) IsDebug() bool   { return core.IsDebug() }
+func (f *FlaskGo) IsDebug() bool   { return f.flags.IsDebug() }
 func (f *FlaskGo) PID() int        { return os.Getpid() }
 
 // Description 描述信息，同时会显示在Swagger文档上
@@ -316,12 +343,8 @@ func (f *FlaskGo) ActivateHotSwitch() *FlaskGo {
 
 	go func() {
 		for range swt {
-			if f.IsDebug() {
-				resetRunMode(false)
-			} else {
-				resetRunMode(true)
-			}
-			f.service.Logger().Debug("Hot-switch received, convert to:", core.GetMode())
+			f.flags.SetMode(!f.IsDebug())
+			f.service.Logger().Debug("Hot-switch received, convert to:", f.flags.GetMode())
 		}
 	}()
 
@@ -350,7 +373,7 @@ func (f *FlaskGo) Shutdown() {
 	}()
 	// Engine().Shutdown() 执行成功后将会直接退出进程，以下代码段仅当超时未关闭时执行到。
 	// Shutdown() 不会关闭设置了 keepalive 的连接，除非设置了 ReadTimeout ，因此设置以下内容以确保关闭.
-	<-time.After(core.ShutdownWithTimeout * time.Second)
+	<-time.After(f.flags.ShutdownWithTimeout)
 	// 此处避免因logger关闭引发错误
 	fmt.Println("Forced shutdown.") // 仅当超时时会到达此行
 }
@@ -374,7 +397,7 @@ func (f *FlaskGo) Run(host, port string) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 
-	if core.DumpPIDEnabled {
+	if f.flags.DumpPIDEnabled {
 		f.DumpPID()
 	}
 
@@ -382,7 +405,7 @@ func (f *FlaskGo) Run(host, port string) {
 	f.Shutdown()
 }
 
-// NewFlaskGo 创建一个WEB服务
+// NewFlaskGo 创建一个WEB服务, 每一次调用均会创建一个独立的应用实例, 多个实例可在同一进程内运行于不同的端口
 //
 //	@param	title	string			Application	name
 //	@param	version	string			Version
@@ -390,21 +413,21 @@ func (f *FlaskGo) Run(host, port string) {
 //	@param	service	CustomService	custom	ServiceContext
 //	@return	*FlaskGo flaskgo对象
 func NewFlaskGo(title, version string, debug bool, svc CustomService) *FlaskGo {
-	core.SetMode(debug)
-
-	once.Do(func() {
-		appEngine = &FlaskGo{
-			title:       title,
-			version:     version,
-			description: title + " Micro Context",
-			service:     &Service{ctx: svc, validate: validator.New()},
-			isStarted:   make(chan struct{}, 1),
-			middlewares: make([]any, 0),
-			events:      make([]*Event, 0),
-		}
-		appEngine.ctx, appEngine.cancel = context.WithCancel(context.Background())
-		appEngine.scheduler = cronjob.NewScheduler(appEngine.ctx, nil)
-	})
+	f := &FlaskGo{
+		title:           title,
+		version:         version,
+		description:     title + " Micro Context",
+		service:         newService(svc),
+		isStarted:       make(chan struct{}, 1),
+		middlewares:     make([]any, 0),
+		events:          make([]*Event, 0),
+		flags:           core.NewFlags(debug),
+		routes:          make(map[string]*Route),
+		responseHeaders: make([]*ResponseHeader, 0),
+		docs:            openapi.NewDocsConfig(),
+	}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	f.scheduler = cronjob.NewScheduler(f.ctx, nil)
 
-	return appEngine
+	return f
 }
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+)
+
+func TestMultipleApps(t *testing.T) {
+	newApp := func(title, path, header string) *FlaskGo {
+		app := NewFlaskGo(title, "1.0.0", false, nil).AddResponseHeader("X-App", header)
+		router := APIRouter("/api", nil)
+		router.GET(path, godantic.String, title, func(c *Context) *Response {
+			return c.StringResponse(title)
+		})
+		app.IncludeRouter(router)
+		return app
+	}
+	public := newApp("public", "/users", "public")
+	admin := newApp("admin", "/settings", "admin").EnableProblemDetails()
+	if public == admin {
+		t.Fatal("expected NewFlaskGo to create independent apps")
+	}
+
+	tests := []struct {
+		app    *FlaskGo
+		path   string
+		status int
+		header string
+	}{
+		{app: public, path: "/api/users", status: http.StatusOK, header: "public"},
+		{app: public, path: "/api/settings", status: http.StatusNotFound},
+		{app: admin, path: "/api/settings", status: http.StatusOK, header: "admin"},
+		{app: admin, path: "/api/users", status: http.StatusNotFound},
+	}
+
+	// 两个应用并发处理请求, 路由, 响应头和错误格式互不影响
+	wg := &sync.WaitGroup{}
+	for _, tt := range tests {
+		tt := tt
+		client := NewTestClient(tt.app)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp := client.Get(tt.path, nil).AssertStatus(t, tt.status)
+				if tt.status != http.StatusOK {
+					if problem := strings.HasPrefix(resp.Header.Get("Content-Type"), string(openapi.MIMEApplicationProblemJSON)); problem != (tt.app == admin) {
+						t.Errorf("%s %s: unexpected content type %q", tt.app.Title(), tt.path, resp.Header.Get("Content-Type"))
+					}
+					return
+				}
+				if resp.Text() != tt.app.Title() || resp.Header.Get("X-App") != tt.header {
+					t.Errorf("%s %s: expected body and header from its own app, got %q %q",
+						tt.app.Title(), tt.path, resp.Text(), resp.Header.Get("X-App"))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
@@ -2,16 +2,13 @@ package app
 
 import (
 	"bytes"
-	"github.com/Chendemo12/flaskgo/internal/core"
-	"github.com/Chendemo12/functools/helper"
+	"github.com/Chendemo12/flaskgo/internal/godantic"
 	"github.com/gofiber/fiber/v2"
 	fiberu "github.com/gofiber/fiber/v2/utils"
+	"reflect"
 	"strings"
 )
 
-var recoverHandler StackTraceHandlerFunc = nil
-var fiberErrorHandler fiber.ErrorHandler = nil // 设置fiber自定义错误处理函数
-
 // HandlerFunc 路由处理函数
 type HandlerFunc = func(s *Context) *Response
 
@@ -29,25 +26,58 @@ type StackTraceHandlerFunc = func(c *fiber.Ctx, e any)
 //
 //	@return	fiber.Handler fiber路由处理方法
 func routeHandler(f HandlerFunc) fiber.Handler {
-	return func(c *fiber.Ctx) error {
+	return func(c *fiber.Ctx) (err error) {
 
+		app := appFromCtx(c)
+		if app == nil { // 路由未通过 FlaskGo 挂载
+			return fiber.NewError(fiber.StatusInternalServerError, "route is not mounted by FlaskGo")
+		}
 		// Acquire Ctx with fiber.Ctx request from pool
-		ctx := appEngine.AcquireCtx(c)
+		ctx := app.AcquireCtx(c)
 		// Release Ctx to pool
-		defer appEngine.ReleaseCtx(ctx)
+		defer app.ReleaseCtx(ctx)
+
+		// 响应写入之后执行依赖项的清理函数, 发生panic时同样执行,
+		// 之后若panic的是可处理的异常则交由 fiber.ErrorHandler 处理, 否则交由 recover 处理
+		outcome := &Outcome{}
+		defer func() {
+			if r := recover(); r != nil {
+				if e, ok := r.(error); ok && app.isException(e) { // 等同于返回 ExceptionResponse
+					outcome.Response, outcome.Err, err = ExceptionResponse(e), e, e
+					ctx.teardownDependencies(outcome)
+					return
+				}
+				outcome.Panic = r
+				ctx.teardownDependencies(outcome)
+				panic(r)
+			}
+			ctx.teardownDependencies(outcome)
+		}()
 
 		// 路由唯一标识: c.Method()+RouteSeparator+c.RelativePath()
 		// c.Route().RelativePath 获取注册的路径，
 		// c.RelativePath() 获取匹配后的请求路由
 		if ctx.route == nil {
-			ctx.route = GetRoute(c.Method(), c.Route().Path) // 获取请求路由
+			ctx.route = app.GetRoute(c.Method(), c.Route().Path) // 获取请求路由
 		}
 
 		if ctx.route != nil { // 存在路由信息
 			resp := routeParamsValidate(ctx, ctx.route) // 路由参数校验
 			if resp != nil {
 				// 路径参数或查询参数校验未通过
-				return c.Status(resp.StatusCode).JSON(resp.Content)
+				return writeErrorContent(c, resp.StatusCode, resp.Content)
+			}
+
+			if app.flags.QueryBindingEnabled { // 开启了查询参数自动绑定
+				resp = queryParamsBind(ctx, ctx.route)
+				if resp != nil {
+					return writeErrorContent(c, resp.StatusCode, resp.Content)
+				}
+			}
+
+			resp = requestBodyBind(ctx, ctx.route) // 文件上传或声明了数据格式的请求体自动绑定
+			if resp != nil {
+				return writeErrorContent(c, resp.StatusCode, resp.Content)
 			}
 
 			//resp = requestBodyMarshal(ctx, route) // 请求体序列化
@@ -55,10 +85,10 @@ func routeHandler(f HandlerFunc) fiber.Handler {
 			//	return c.Status(resp.StatusCode).JSON(resp.Content)
 			//}
 
-			if !core.RequestValidateDisabled { // 开启了请求体自动校验
+			if !app.flags.RequestValidateDisabled { // 开启了请求体自动校验
 				resp = ctx.Validate(ctx.RequestBody)
 				if resp != nil {
-					return c.Status(resp.StatusCode).JSON(resp.Content)
+					return writeErrorContent(c, resp.StatusCode, resp.Content)
 				}
 			}
 
@@ -66,13 +96,17 @@ func routeHandler(f HandlerFunc) fiber.Handler {
 			// 处理依赖项
 			resp = dependencyDone(ctx, ctx.route)
 			if resp != nil {
-				return responseWriter(c, resp) // 返回消息流
+				outcome.Response = resp
+				outcome.Err = responseWriter(ctx, resp) // 返回消息流
+				return outcome.Err
 			}
 		}
 		//
 		// 执行处理函数并获取返回值
 		if resp := f(ctx); resp != nil { // 自定义函数存在返回值
-			return responseWriter(c, resp) // 返回消息流
+			outcome.Response = resp
+			outcome.Err = responseWriter(ctx, resp) // 返回消息流
+			return outcome.Err
 		}
 
 		// 自定义函数无任何返回值
@@ -80,95 +114,260 @@ func routeHandler(f HandlerFunc) fiber.Handler {
 	}
 }
 
-// jsoniterUnmarshalErrorToValidationError 将jsoniter 的反序列化错误转换成 接口错误类型
-func jsoniterUnmarshalErrorToValidationError(err error) *ValidationError {
-	// jsoniter 的反序列化错误格式：
-	//
-	// jsoniter.iter.ReportError():224
-	//
-	// 	iter.Error = fmt.Errorf("%s: %s, error found in #%v byte of ...|%s|..., bigger context ...|%s|...",
-	//		operation, msg, iter.head-peekStart, parsing, context)
-	//
-	// 	err.Error():
-	//
-	// 	main.SimpleForm.name: ReadString: expects " or n, but found 2, error found in #10 byte of ...| "name": 23,
-	//		"a|..., bigger context ...|{
-	//		"name": 23,
-	//		"age": "23",
-	//		"sex": "F"
-	// 	}|...
-	msg := err.Error()
-	ve := &ValidationError{Loc: []string{"body"}}
-	for i := 0; i < len(msg); i++ {
-		if msg[i:i+1] == ":" {
-			ve.Loc = append(ve.Loc, msg[:i])
+// routeParamsValidate 路径参数、查询参数、请求头参数和cookie参数校验
+// 参数值会依据其定义的类型进行转换和范围校验, 原始值记录在 Context.PathFields 和 Context.QueryFields 中,
+// 转换后的值分别记录在 Context.PathValues, Context.QueryValues, Context.HeaderFields 和 Context.CookieFields 中
+func routeParamsValidate(ctx *Context, route *Route) *Response {
+	// 路径参数校验
+	for i := 0; i < len(route.PathFields); i++ {
+		name := route.PathFields[i].SchemaName()
+		raw := ctx.Context().Params(name)
+		if raw == "" {
+			if route.PathFields[i].IsRequired() {
+				// 不存在此路径参数, 但是此路径参数设置为必选
+				return ValidationErrorResponse(&ValidationError{
+					Loc:  []string{godantic.InPath, name},
+					Msg:  ctx.app.service.translateMessage(msgPathRequired, ctx.Language()),
+					Type: string(route.PathFields[i].SchemaType()),
+					Ctx:  emptyMap,
+				})
+			}
+			continue
+		}
+
+		v, ve := route.PathFields[i].ParseValue(raw)
+		if ve != nil {
+			return ValidationErrorResponse(ctx.validationError(ve))
+		}
+		ctx.PathFields[name] = raw
+		ctx.PathValues[name] = v
+	}
+
+	// 查询参数校验
+	if resp := paramsValidate(ctx, route.QueryFields, ctx.QueryValues, ctx.QueryFields, func(name string) []string {
+		return queryValues(ctx.Context(), name)
+	}); resp != nil {
+		return resp
+	}
+
+	// 请求头参数校验
+	if resp := paramsValidate(ctx, route.HeaderFields, ctx.HeaderFields, nil, func(name string) []string {
+		return nonEmptyValues(ctx.Context().Get(name))
+	}); resp != nil {
+		return resp
+	}
+
+	// cookie参数校验
+	if resp := paramsValidate(ctx, route.CookieFields, ctx.CookieFields, nil, func(name string) []string {
+		return nonEmptyValues(ctx.Context().Cookies(name))
+	}); resp != nil {
+		return resp
+	}
+
+	return nil
+}
+
+// paramsValidate 校验并转换查询参数、请求头参数或cookie参数
+//
+//	@param	ctx		*Context						请求上下文, 用于确定错误信息的语言
+//	@param	fields	[]*godantic.QModel				参数定义
+//	@param	store	map[string]any					转换后参数值的存储位置
+//	@param	raw		map[string]string				原始参数值的存储位置, 为nil则不记录
+//	@param	lookup	func(name string) []string		参数取值方法，返回参数的全部非空原始值
+//	@return	*Response 校验错误
+func paramsValidate(ctx *Context, fields []*godantic.QModel, store map[string]any, raw map[string]string, lookup func(name string) []string) *Response {
+	for i := 0; i < len(fields); i++ {
+		name := fields[i].SchemaName()
+		values := lookup(name)
+		if len(values) == 0 {
+			if fields[i].IsRequired() {
+				// 但是此参数设置为必选
+				return ValidationErrorResponse(&ValidationError{
+					Loc:  []string{fields[i].Location(), name},
+					Msg:  ctx.app.service.translateMessage(paramRequiredMessage(fields[i].Location()), ctx.Language()),
+					Type: string(fields[i].SchemaType()),
+					Ctx:  emptyMap,
+				})
+			}
+			// 可选参数，存在默认值时以默认值填充
+			dv := godantic.QueryFieldTag(fields[i].Tag, "default", "")
+			if dv == "" {
+				continue
+			}
+			values = append(values, dv)
+		}
+
+		v, ve := fields[i].ParseValue(values...)
+		if ve != nil {
+			return ValidationErrorResponse(ctx.validationError(ve))
+		}
+		store[name] = v
+		if raw != nil {
+			raw[name] = values[0]
+		}
+	}
+
+	return nil
+}
+
+// queryParamsBind 创建一个新的查询参数结构体实例，绑定并校验查询参数, 结果记录在 Context.QueryParams 中
+func queryParamsBind(ctx *Context, route *Route) *Response {
+	if route.QueryModel == nil {
+		return nil
+	}
+
+	rt := reflect.TypeOf(route.QueryModel)
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct { // 仅支持结构体类型的查询参数
+		return nil
+	}
+
+	stc := reflect.New(rt).Interface()
+	if resp := ctx.ShouldBindQuery(stc); resp != nil {
+		return resp
+	}
+	ctx.QueryParams = stc
+
+	return nil
+}
+
+// requestBodyBind 依据请求头 Content-Type 将请求体绑定到路由的请求体模型, 并将其设置为 Context.RequestBody
+// 仅对文件上传模型或通过 Route.SetContentTypes 声明了数据格式的路由有效, 不支持的数据格式返回415错误
+func requestBodyBind(ctx *Context, route *Route) *Response {
+	if route.RequestModel == nil {
+		return nil
+	}
+	contentTypes := route.ContentTypes
+	if godantic.IsMultipart(route.RequestModel) { // 文件上传模型恒为 multipart/form-data
+		contentTypes = []string{fiber.MIMEMultipartForm}
+	}
+	if len(contentTypes) == 0 {
+		return nil
+	}
+
+	rt := reflect.TypeOf(route.RequestModel)
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct { // 仅支持结构体类型的请求体
+		return nil
+	}
+
+	mediaType := requestMediaType(ctx.ec)
+	supported := false
+	for _, ct := range contentTypes {
+		if strings.EqualFold(ct, mediaType) {
+			supported = true
 			break
 		}
 	}
-	if msgs := strings.Split(msg, jsoniterUnmarshalErrorSeparator); len(msgs) > 0 {
-		_ = helper.DefaultJsonUnmarshal([]byte(msgs[jsonErrorFormIndex]), &ve.Ctx)
-		ve.Msg = msgs[jsonErrorFieldMsgIndex][len(ve.Loc[1])+2:]
-		if s := strings.Split(ve.Msg, ":"); len(s) > 0 {
-			ve.Type = s[0]
+	if !supported {
+		return &Response{
+			StatusCode: fiber.StatusUnsupportedMediaType,
+			Content: &HTTPValidationError{Detail: []*ValidationError{{
+				Ctx:  map[string]any{"supported": contentTypes, "received": mediaType},
+				Msg:  ctx.app.service.translateMessage(msgUnsupportedMediaType, ctx.Language()),
+				Type: string(godantic.StringType),
+				Loc:  []string{godantic.InHeader, fiber.HeaderContentType},
+			}}},
+			Type: ErrResponseType,
 		}
 	}
 
-	return ve
+	stc := reflect.New(rt).Interface()
+	if resp := ctx.ShouldBind(stc); resp != nil {
+		return resp
+	}
+	ctx.RequestBody = stc
+
+	return nil
 }
 
-// routeParamsValidate 路径参数和查询参数校验
-func routeParamsValidate(ctx *Context, route *Route) *Response {
-	// 路径参数校验
-	for i := 0; i < len(route.PathFields); i++ {
-		ctx.PathFields[route.PathFields[i].Title] = ctx.Context().Params(route.PathFields[i].Title)
-		if route.PathFields[i].IsRequired() && ctx.PathFields[route.PathFields[i].Title] == "" {
-			// 不存在此路径参数, 但是此路径参数设置为必选
-			return ValidationErrorResponse(&ValidationError{
-				Loc:  []string{"path", route.PathFields[i].Title},
-				Msg:  "path must not be empty",
-				Type: "string",
-				Ctx:  nil,
-			})
+// requestMediaType 获取请求体的数据格式, 不包含 charset 等参数
+func requestMediaType(c *fiber.Ctx) string {
+	ct := string(c.Request().Header.ContentType())
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
+
+// formValues 获取 application/x-www-form-urlencoded 表单字段的全部非空原始值
+func formValues(c *fiber.Ctx, name string) []string {
+	values := make([]string, 0)
+	for _, v := range c.Request().PostArgs().PeekMulti(name) {
+		if len(v) > 0 {
+			values = append(values, string(v))
 		}
 	}
+	return values
+}
 
-	// 查询参数校验
-	for i := 0; i < len(route.QueryFields); i++ {
-		ctx.QueryFields[route.QueryFields[i].Title] = ctx.Context().Query(route.QueryFields[i].Title)
-		if route.QueryFields[i].IsRequired() && ctx.QueryFields[route.QueryFields[i].Title] == "" {
-			// 但是此查询参数设置为必选
-			return ValidationErrorResponse(&ValidationError{
-				Loc:  []string{"query", route.QueryFields[i].Title},
-				Msg:  "query must not be empty",
-				Type: "string",
-				Ctx:  nil,
-			})
+// queryValues 获取查询参数的全部非空原始值, 对于数组类型的参数允许多次出现, 如: ?id=1&id=2
+func queryValues(c *fiber.Ctx, name string) []string {
+	values := make([]string, 0)
+	for _, v := range c.Context().QueryArgs().PeekMulti(name) {
+		if len(v) > 0 {
+			values = append(values, string(v))
 		}
 	}
+	return values
+}
 
-	return nil
+// nonEmptyValues 过滤空值
+func nonEmptyValues(values ...string) []string {
+	vs := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// isSuccessStatus 是否是成功的响应状态码
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= fiber.StatusOK && statusCode < fiber.StatusMultipleChoices
 }
 
+// dependencyDone 执行路由的依赖项, 依次执行认证依赖项 Route.Securities, Route.Dependencies 和 Route.DependsOn 依赖图
 func dependencyDone(ctx *Context, route *Route) *Response {
+	for i := 0; i < len(route.Securities); i++ {
+		if resp := ctx.app.security(route.Securities[i]).Authenticate(ctx); resp != nil {
+			return resp
+		}
+	}
 	for i := 0; i < len(route.Dependencies); i++ {
 		if resp := route.Dependencies[i](ctx); resp != nil {
 			return resp
 		}
 	}
+	for i := 0; i < len(route.DependsOn); i++ {
+		if resp := ctx.resolveDependency(route.DependsOn[i]); resp != nil {
+			return resp
+		}
+	}
 
 	return nil
 }
 
-func responseWriter(c *fiber.Ctx, resp *Response) error {
+// responseWriter 写入响应体, 对于成功的 JSON 响应会首先依据路由的 ResponseModel 校验返回值,
+// 校验未通过时不会发送返回值，而是返回500错误
+func responseWriter(ctx *Context, resp *Response) error {
+	c := ctx.Context()
+
 	switch resp.Type {
 
 	case JsonResponseType: // Json类型
-		if core.ResponseValidateDisabled {
-			return c.Status(resp.StatusCode).JSON(resp.Content)
-		} else {
-			// TODO: implement 响应体校验
-			return c.Status(resp.StatusCode).JSON(resp.Content)
+		if !ctx.app.flags.ResponseValidateDisabled {
+			if ves := ctx.structResponseValidation(resp.StatusCode, resp.Content); len(ves) > 0 {
+				resp = ResponseValidationErrorResponse(ves...)
+				return writeErrorContent(c, resp.StatusCode, resp.Content)
+			}
 		}
+		return c.Status(resp.StatusCode).JSON(resp.Content)
 
 	case StringResponseType:
 		return c.Status(resp.StatusCode).SendString(resp.Content.(string))
@@ -179,7 +378,7 @@ func responseWriter(c *fiber.Ctx, resp *Response) error {
 		return c.Status(resp.StatusCode).SendString(resp.Content.(string))
 
 	case ErrResponseType:
-		return c.Status(resp.StatusCode).JSON(resp.Content)
+		return writeErrorContent(c, resp.StatusCode, resp.Content)
 
 	case StreamResponseType: // 返回字节流
 		return c.SendStream(bytes.NewReader(resp.Content.([]byte)))
@@ -190,6 +389,9 @@ func responseWriter(c *fiber.Ctx, resp *Response) error {
 	case AdvancedResponseType:
 		return resp.Content.(fiber.Handler)(c)
 
+	case ExceptionResponseType: // 交由 fiber.ErrorHandler 处理
+		return resp.Content.(error)
+
 	case CustomResponseType:
 		c.Status(resp.StatusCode).Set(fiber.HeaderContentType, resp.ContentType)
 		switch resp.ContentType {
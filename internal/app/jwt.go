@@ -0,0 +1,421 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const ( // JWT 签名算法
+	JWTAlgHS256 = "HS256"
+	JWTAlgRS256 = "RS256"
+	JWTAlgES256 = "ES256"
+)
+
+var (
+	ErrJWTMalformed         = errors.New("token is malformed")
+	ErrJWTAlgorithm         = errors.New("token signing algorithm is not allowed")
+	ErrJWTKeyNotFound       = errors.New("no key found for token")
+	ErrJWTSignature         = errors.New("token signature is invalid")
+	ErrJWTExpired           = errors.New("token is expired")
+	ErrJWTNotValidYet       = errors.New("token is not valid yet")
+	ErrJWTInvalidAudience   = errors.New("token has invalid audience")
+	ErrJWTInvalidIssuer     = errors.New("token has invalid issuer")
+	ErrJWTInsufficientScope = errors.New("token has insufficient scope")
+)
+
+// JWTClaims JWT 载荷
+type JWTClaims map[string]any
+
+// Get 获取一个声明
+func (c JWTClaims) Get(key string) (any, bool) {
+	v, ok := c[key]
+	return v, ok
+}
+
+// Subject 令牌主体, 即 sub 声明
+func (c JWTClaims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer 令牌签发者, 即 iss 声明
+func (c JWTClaims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience 令牌受众, 即 aud 声明, 允许为字符串或字符串数组
+func (c JWTClaims) Audience() []string { return claimStrings(c["aud"]) }
+
+// Scopes 令牌的授权范围, 依次查找 scope(以空格分隔的字符串), scp 和 scopes 声明
+func (c JWTClaims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	if v, ok := c["scp"]; ok {
+		return claimStrings(v)
+	}
+	return claimStrings(c["scopes"])
+}
+
+// numericDate 获取时间类型的声明, 如: exp, nbf 和 iat
+//
+//	@return	time.Time 声明的时间
+//	@return	bool 声明是否存在
+//	@return	error 声明存在但不是 NumericDate 时返回 ErrJWTMalformed
+func (c JWTClaims) numericDate(key string) (time.Time, bool, error) {
+	value, found := c[key]
+	if !found {
+		return time.Time{}, false, nil
+	}
+
+	switch v := value.(type) {
+	case float64:
+		sec, frac := int64(v), v-float64(int64(v))
+		return time.Unix(sec, int64(frac*1e9)), true, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, true, ErrJWTMalformed
+		}
+		return time.Unix(int64(f), 0), true, nil
+	default:
+		return time.Time{}, true, ErrJWTMalformed
+	}
+}
+
+func claimStrings(v any) []string {
+	switch s := v.(type) {
+	case string:
+		return []string{s}
+	case []string:
+		return s
+	case []any:
+		m := make([]string, 0, len(s))
+		for _, e := range s {
+			if str, ok := e.(string); ok {
+				m = append(m, str)
+			}
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+// JWTKey JWT 验签密钥
+type JWTKey struct {
+	Key any    // HS256 为 []byte, RS256 为 *rsa.PublicKey, ES256 为 *ecdsa.PublicKey
+	Kid string // 密钥ID, 为空时匹配任意 kid
+	Alg string // 签名算法: HS256, RS256 或 ES256
+}
+
+// JWTAuth JWT 认证, 令牌通过 Authorization: Bearer 请求头传递
+// 校验签名及 exp, nbf, aud 和 iss 声明，并校验令牌是否包含路由通过 Route.SetScopes 声明的全部授权范围,
+// 校验通过后载荷保存于 Context.Claims, 令牌字符串保存于 Context.Credentials
+type JWTAuth struct {
+	verify      func(c *Context, claims JWTClaims) *Response
+	Keys        []*JWTKey         // 验签密钥集合
+	Issuer      string            // 令牌签发者, 为空时不校验
+	Audience    []string          // 允许的令牌受众, 为空时不校验
+	Leeway      time.Duration     // 校验 exp 和 nbf 时允许的时钟误差
+	TokenUrl    string            // 获取令牌的路由, 若不为空则在文档中以 OAuth2 密码模式描述
+	Scopes      map[string]string // 全部的授权范围及其说明, 仅用于文档
+	Description string            // 认证说明
+}
+
+// JWTBearer JWT 认证
+//
+//	@param	keys	[]*JWTKey	验签密钥集合
+func JWTBearer(keys ...*JWTKey) *JWTAuth {
+	return &JWTAuth{Keys: keys, Scopes: map[string]string{}}
+}
+
+// SetVerify 设置令牌载荷的自定义校验方法, 此方法在签名和标准声明校验通过后执行
+func (a *JWTAuth) SetVerify(verify func(c *Context, claims JWTClaims) *Response) *JWTAuth {
+	a.verify = verify
+	return a
+}
+
+func (a *JWTAuth) SchemeName() string { return "JWTBearer" }
+
+func (a *JWTAuth) Scheme() *openapi.SecurityScheme {
+	if a.TokenUrl != "" {
+		return &openapi.SecurityScheme{
+			Type:        openapi.SecurityTypeOAuth2,
+			Description: a.Description,
+			Flows: &openapi.OAuthFlows{
+				Password: &openapi.OAuthFlow{Scopes: a.Scopes, TokenUrl: a.TokenUrl},
+			},
+		}
+	}
+	return &openapi.SecurityScheme{
+		Type: openapi.SecurityTypeHTTP, Scheme: "bearer", BearerFormat: "JWT", Description: a.Description,
+	}
+}
+
+// Authenticate 校验令牌及路由所需的授权范围
+func (a *JWTAuth) Authenticate(c *Context) *Response {
+	scheme, token := authorization(c)
+	if !strings.EqualFold(scheme, "bearer") || token == "" {
+		return UnauthorizedResponse("Bearer", NotAuthenticated)
+	}
+
+	claims, err := a.Parse(token)
+	if err != nil {
+		return UnauthorizedResponse(
+			fmt.Sprintf(`Bearer error="invalid_token", error_description="%s"`, err.Error()), err.Error(),
+		)
+	}
+
+	if c.route != nil && len(c.route.Scopes) > 0 {
+		if missing := missingScopes(c.route.Scopes, claims.Scopes()); len(missing) > 0 {
+			return ForbiddenResponse(
+				fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(c.route.Scopes, " ")),
+				ErrJWTInsufficientScope.Error(),
+			)
+		}
+	}
+
+	if a.verify != nil {
+		if resp := a.verify(c, claims); resp != nil {
+			return resp
+		}
+	}
+
+	c.Credentials = token
+	c.Claims = claims
+	return nil
+}
+
+// Parse 解析并校验令牌, 返回令牌载荷
+//
+//	@param	token	string	令牌字符串
+//	@return	JWTClaims 令牌载荷
+//	@return	error 令牌格式, 签名或声明错误
+func (a *JWTAuth) Parse(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	claims := JWTClaims{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	// 算法必须与密钥声明的算法一致，以避免算法混淆攻击
+	if header.Alg != JWTAlgHS256 && header.Alg != JWTAlgRS256 && header.Alg != JWTAlgES256 {
+		return nil, ErrJWTAlgorithm
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	found := false
+	for _, key := range a.Keys {
+		if key.Alg != header.Alg || (header.Kid != "" && key.Kid != "" && key.Kid != header.Kid) {
+			continue
+		}
+		found = true
+		if verifyJWTSignature(key, signingInput, signature) {
+			if err := a.validateClaims(claims); err != nil {
+				return nil, err
+			}
+			return claims, nil
+		}
+	}
+	if !found {
+		return nil, ErrJWTKeyNotFound
+	}
+
+	return nil, ErrJWTSignature
+}
+
+// validateClaims 校验 exp, nbf, aud 和 iss 声明, exp 和 nbf 必须为 NumericDate
+func (a *JWTAuth) validateClaims(claims JWTClaims) error {
+	now := time.Now()
+	// 声明存在但不是数字时视为格式错误, 而非忽略此声明, 否则 "exp":"never" 的令牌将永不过期
+	exp, found, err := claims.numericDate("exp")
+	if err != nil {
+		return err
+	}
+	if found && now.After(exp.Add(a.Leeway)) {
+		return ErrJWTExpired
+	}
+	nbf, found, err := claims.numericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if found && now.Before(nbf.Add(-a.Leeway)) {
+		return ErrJWTNotValidYet
+	}
+	if a.Issuer != "" && claims.Issuer() != a.Issuer {
+		return ErrJWTInvalidIssuer
+	}
+	// 令牌受众中至少存在一个允许的受众
+	if len(a.Audience) > 0 && len(missingScopes(a.Audience, claims.Audience())) == len(a.Audience) {
+		return ErrJWTInvalidAudience
+	}
+
+	return nil
+}
+
+// SignJWT 签发令牌
+//
+//	@param	claims	JWTClaims	令牌载荷
+//	@param	alg		string		签名算法: HS256, RS256 或 ES256
+//	@param	key		any			签名密钥, HS256 为 []byte, RS256 为 *rsa.PrivateKey, ES256 为 *ecdsa.PrivateKey
+//	@param	kid		string		密钥ID, 允许为空
+//	@return	string 令牌字符串
+func SignJWT(claims JWTClaims, alg string, key any, kid string) (string, error) {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	hb, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	cb, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		if alg != JWTAlgHS256 {
+			return "", ErrJWTAlgorithm
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+
+	case *rsa.PrivateKey:
+		if alg != JWTAlgRS256 {
+			return "", ErrJWTAlgorithm
+		}
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+
+	case *ecdsa.PrivateKey:
+		if alg != JWTAlgES256 {
+			return "", ErrJWTAlgorithm
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return "", err
+		}
+		// 签名为定长的 r||s
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+	default:
+		return "", ErrJWTAlgorithm
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// verifyJWTSignature 校验签名
+func verifyJWTSignature(key *JWTKey, signingInput, signature []byte) bool {
+	switch key.Alg {
+	case JWTAlgHS256:
+		secret, ok := key.Key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		return hmac.Equal(signature, mac.Sum(nil))
+
+	case JWTAlgRS256:
+		pub, ok := key.Key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(signingInput)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+
+	case JWTAlgES256:
+		pub, ok := key.Key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+
+	default:
+		return false
+	}
+}
+
+func decodeJWTSegment(seg string, v any) error {
+	bs, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
+
+// missingScopes 返回 required 中不存在于 granted 的元素
+func missingScopes(required, granted []string) []string {
+	missing := make([]string, 0)
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if r == g {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// ForbiddenResponse 权限不足返回值, 状态码为403
+//
+//	@param	challenge	string	WWW-Authenticate 响应头, 为空时不设置
+//	@param	detail		string	错误信息
+//	@return	resp *Response response返回体
+func ForbiddenResponse(challenge, detail string) *Response {
+	return AdvancedResponse(fiber.StatusForbidden, func(c *fiber.Ctx) error {
+		if challenge != "" {
+			c.Set(fiber.HeaderWWWAuthenticate, challenge)
+		}
+		return writeErrorDetail(c, fiber.StatusForbidden, detail)
+	})
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+type jwtTestKeys struct {
+	secret []byte
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+}
+
+func newJWTTestKeys(t *testing.T) *jwtTestKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &jwtTestKeys{secret: []byte("secret"), rsa: rsaKey, ec: ecKey}
+}
+
+func (k *jwtTestKeys) auth() *JWTAuth {
+	return JWTBearer(
+		&JWTKey{Alg: JWTAlgHS256, Key: k.secret},
+		&JWTKey{Alg: JWTAlgRS256, Key: &k.rsa.PublicKey, Kid: "rsa"},
+		&JWTKey{Alg: JWTAlgES256, Key: &k.ec.PublicKey},
+	)
+}
+
+func signTestJWT(t *testing.T, claims JWTClaims, alg string, key any, kid string) string {
+	t.Helper()
+	token, err := SignJWT(claims, alg, key, kid)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestJWTAuthParse(t *testing.T) {
+	keys := newJWTTestKeys(t)
+	otherRsa, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherEc, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name   string
+		claims JWTClaims
+		alg    string
+		key    any
+		kid    string
+		auth   func(a *JWTAuth)
+		err    error
+	}{
+		{name: "HS256", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgHS256, key: keys.secret},
+		{name: "HS256 wrong secret", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgHS256, key: []byte("other"), err: ErrJWTSignature},
+		{name: "RS256", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgRS256, key: keys.rsa, kid: "rsa"},
+		{name: "RS256 wrong key", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgRS256, key: otherRsa, err: ErrJWTSignature},
+		{name: "RS256 unknown kid", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgRS256, key: keys.rsa, kid: "other", err: ErrJWTKeyNotFound},
+		{name: "ES256", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgES256, key: keys.ec},
+		{name: "ES256 wrong key", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgES256, key: otherEc, err: ErrJWTSignature},
+		{
+			name: "algorithm without key", claims: JWTClaims{"sub": "lee"}, alg: JWTAlgES256, key: keys.ec,
+			auth: func(a *JWTAuth) { a.Keys = a.Keys[:1] }, err: ErrJWTKeyNotFound,
+		},
+		{name: "not expired", claims: JWTClaims{"exp": now + 60}, alg: JWTAlgHS256, key: keys.secret},
+		{name: "expired", claims: JWTClaims{"exp": now - 60}, alg: JWTAlgHS256, key: keys.secret, err: ErrJWTExpired},
+		{
+			name: "expired within leeway", claims: JWTClaims{"exp": now - 60}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Leeway = 2 * time.Minute },
+		},
+		{name: "exp not numeric", claims: JWTClaims{"exp": "never"}, alg: JWTAlgHS256, key: keys.secret, err: ErrJWTMalformed},
+		{name: "exp null", claims: JWTClaims{"exp": nil}, alg: JWTAlgHS256, key: keys.secret, err: ErrJWTMalformed},
+		{name: "nbf reached", claims: JWTClaims{"nbf": now - 60}, alg: JWTAlgHS256, key: keys.secret},
+		{name: "nbf in future", claims: JWTClaims{"nbf": now + 60}, alg: JWTAlgHS256, key: keys.secret, err: ErrJWTNotValidYet},
+		{name: "nbf not numeric", claims: JWTClaims{"nbf": "soon"}, alg: JWTAlgHS256, key: keys.secret, err: ErrJWTMalformed},
+		{
+			name: "audience string", claims: JWTClaims{"aud": "api"}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Audience = []string{"api", "web"} },
+		},
+		{
+			name: "audience array", claims: JWTClaims{"aud": []string{"mobile", "web"}}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Audience = []string{"api", "web"} },
+		},
+		{
+			name: "audience mismatch", claims: JWTClaims{"aud": "mobile"}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Audience = []string{"api"} }, err: ErrJWTInvalidAudience,
+		},
+		{
+			name: "audience missing", claims: JWTClaims{}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Audience = []string{"api"} }, err: ErrJWTInvalidAudience,
+		},
+		{
+			name: "issuer", claims: JWTClaims{"iss": "flaskgo"}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Issuer = "flaskgo" },
+		},
+		{
+			name: "issuer mismatch", claims: JWTClaims{"iss": "other"}, alg: JWTAlgHS256, key: keys.secret,
+			auth: func(a *JWTAuth) { a.Issuer = "flaskgo" }, err: ErrJWTInvalidIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := keys.auth()
+			if tt.auth != nil {
+				tt.auth(auth)
+			}
+
+			claims, err := auth.Parse(signTestJWT(t, tt.claims, tt.alg, tt.key, tt.kid))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && claims.Subject() != tt.claims.Subject() {
+				t.Errorf("expected subject %q, got %q", tt.claims.Subject(), claims.Subject())
+			}
+		})
+	}
+}
+
+func TestJWTAuthParseMalformed(t *testing.T) {
+	auth := JWTBearer(&JWTKey{Alg: JWTAlgHS256, Key: []byte("secret")})
+	token := signTestJWT(t, JWTClaims{"sub": "lee"}, JWTAlgHS256, []byte("secret"), "")
+	parts := strings.Split(token, ".")
+
+	tests := map[string]struct {
+		token string
+		err   error
+	}{
+		"two segments":      {token: parts[0] + "." + parts[1], err: ErrJWTMalformed},
+		"invalid header":    {token: "x." + parts[1] + "." + parts[2], err: ErrJWTMalformed},
+		"invalid signature": {token: parts[0] + "." + parts[1] + ".!", err: ErrJWTMalformed},
+		"alg none":          {token: "eyJhbGciOiJub25lIn0." + parts[1] + ".", err: ErrJWTAlgorithm},
+		"tampered claims":   {token: parts[0] + ".eyJzdWIiOiJyb290In0." + parts[2], err: ErrJWTSignature},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := auth.Parse(tt.token); !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestJWTAuthAuthenticate(t *testing.T) {
+	secret := []byte("secret")
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+		return c.StringResponse(c.Claims.Subject())
+	}).AddSecurity(JWTBearer(&JWTKey{Alg: JWTAlgHS256, Key: secret})).SetScopes("read", "write")
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	future := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name      string
+		header    string
+		status    int
+		challenge string
+		body      string
+	}{
+		{
+			name: "ok", status: http.StatusOK, body: "lee",
+			header: "Bearer " + signTestJWT(t, JWTClaims{"sub": "lee", "exp": future, "scope": "read write"}, JWTAlgHS256, secret, ""),
+		},
+		{name: "no token", header: "", status: http.StatusUnauthorized, challenge: "Bearer"},
+		{name: "basic scheme", header: "Basic bGVlOnB3ZA==", status: http.StatusUnauthorized, challenge: "Bearer"},
+		{
+			name: "expired", status: http.StatusUnauthorized,
+			header:    "Bearer " + signTestJWT(t, JWTClaims{"sub": "lee", "exp": 1, "scope": "read write"}, JWTAlgHS256, secret, ""),
+			challenge: `Bearer error="invalid_token", error_description="token is expired"`,
+		},
+		{
+			name: "exp never", status: http.StatusUnauthorized,
+			header:    "Bearer " + signTestJWT(t, JWTClaims{"sub": "lee", "exp": "never", "scope": "read write"}, JWTAlgHS256, secret, ""),
+			challenge: `Bearer error="invalid_token", error_description="token is malformed"`,
+		},
+		{
+			name: "missing scope", status: http.StatusForbidden,
+			header:    "Bearer " + signTestJWT(t, JWTClaims{"sub": "lee", "exp": future, "scp": []string{"read"}}, JWTAlgHS256, secret, ""),
+			challenge: `Bearer error="insufficient_scope", scope="read write"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp := client.Do(req).AssertStatus(t, tt.status)
+			if got := resp.Header.Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("expected WWW-Authenticate %q, got %q", tt.challenge, got)
+			}
+			if tt.body != "" && resp.Text() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, resp.Text())
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package app
 import (
 	"errors"
 	"fmt"
-	"github.com/Chendemo12/flaskgo/internal/core"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/gofiber/fiber/v2"
 	"os"
+	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,28 +21,64 @@ func (f *FlaskGo) AcquireCtx(fctx *fiber.Ctx) *Context {
 	c.RequestBody = int64(1) // 初始化为1，避免访问错误
 	c.PathFields = map[string]string{}
 	c.QueryFields = map[string]string{}
+	c.PathValues = map[string]any{}
+	c.QueryValues = map[string]any{}
+	c.HeaderFields = map[string]any{}
+	c.CookieFields = map[string]any{}
+	c.dependencies = map[*Dependency]any{}
+	c.dependencyTypes = map[reflect.Type]any{}
 	return c
 }
 
 // ReleaseCtx 释放并归还 Context
 func (f *FlaskGo) ReleaseCtx(ctx *Context) {
 	ctx.ec = nil
+	ctx.route = nil
 	ctx.RequestBody = int64(1)
+	ctx.QueryParams = nil
+	ctx.Credentials = nil
+	ctx.Claims = nil
+	ctx.dependencies = nil
+	ctx.dependencyTypes = nil
+	ctx.resolving = nil
+	ctx.cleanups = nil
 	ctx.PathFields = nil
 	ctx.QueryFields = nil
+	ctx.PathValues = nil
+	ctx.QueryValues = nil
+	ctx.HeaderFields = nil
+	ctx.CookieFields = nil
 
 	f.pool.Put(ctx)
 }
 
 // ReplaceErrorHandler 替换fiber错误处理方法，是 请求错误处理方法
 func (f *FlaskGo) ReplaceErrorHandler(fc fiber.ErrorHandler) *FlaskGo {
-	fiberErrorHandler = fc
+	f.errorHandler = fc
+	return f
+}
+
+// AddExceptionHandler 添加异常处理函数, 路由函数和依赖项通过 ExceptionResponse 返回或 panic 的错误,
+// 若通过 errors.As 能够匹配到 errType 的类型, 则交由 fn 转换为响应, 先注册的优先匹配
+// 亦可为 *HTTPException 注册处理函数以替换其默认的响应格式
+//
+//	@param	errType	error					错误类型的一个实例, 如: &NotFoundError{} 或 (*NotFoundError)(nil)
+//	@param	fn		ExceptionHandlerFunc	异常处理函数, 若返回nil则继续使用默认的处理方式
+//
+//	app.AddExceptionHandler(&NotFoundError{}, func(c *flaskgo.Context, err error) *flaskgo.Response {
+//		return c.JSONResponse(http.StatusNotFound, map[string]string{"detail": err.(*NotFoundError).Name + " not found"})
+//	})
+func (f *FlaskGo) AddExceptionHandler(errType error, fn ExceptionHandlerFunc) *FlaskGo {
+	if errType == nil || fn == nil {
+		return f
+	}
+	f.exceptionHandlers = append(f.exceptionHandlers, &exceptionHandler{rtype: reflect.TypeOf(errType), fn: fn})
 	return f
 }
 
 // ReplaceStackTraceHandler 替换错误堆栈处理函数，即 recover 方法
 func (f *FlaskGo) ReplaceStackTraceHandler(fc StackTraceHandlerFunc) *FlaskGo {
-	recoverHandler = fc
+	f.recoverHandler = fc
 	return f
 }
 
@@ -55,14 +93,14 @@ func (f *FlaskGo) ReplaceRecover(fc StackTraceHandlerFunc) *FlaskGo {
 //	@param	value	string	值
 func (f *FlaskGo) AddResponseHeader(key, value string) *FlaskGo {
 	// 首先判定是否已经存在
-	for i := 0; i < len(responseHeaders); i++ {
-		if responseHeaders[i].Key == key {
-			responseHeaders[i].Value = value
+	for i := 0; i < len(f.responseHeaders); i++ {
+		if f.responseHeaders[i].Key == key {
+			f.responseHeaders[i].Value = value
 			return f
 		}
 	}
 	// 不存在，新建
-	responseHeaders = append(responseHeaders, &ResponseHeader{
+	f.responseHeaders = append(f.responseHeaders, &ResponseHeader{
 		Key:   key,
 		Value: value,
 	})
@@ -73,9 +111,9 @@ func (f *FlaskGo) AddResponseHeader(key, value string) *FlaskGo {
 //
 //	@param	key	string	键
 func (f *FlaskGo) DeleteResponseHeader(key string) *FlaskGo {
-	for i := 0; i < len(responseHeaders); i++ {
-		if responseHeaders[i].Key == key {
-			responseHeaders[i].Value = ""
+	for i := 0; i < len(f.responseHeaders); i++ {
+		if f.responseHeaders[i].Key == key {
+			f.responseHeaders[i].Value = ""
 		}
 	}
 	return f
@@ -93,48 +131,221 @@ func (f *FlaskGo) RunCronjob(_ func(ctx *Service) error) *FlaskGo {
 //
 //	@param	timeout	in	修改关机前最大等待时间,	单位秒
 func (f *FlaskGo) SetShutdownTimeout(timeout int) *FlaskGo {
-	core.ShutdownWithTimeout = time.Duration(timeout)
+	f.flags.ShutdownWithTimeout = time.Duration(timeout) * time.Second
 	return f
 }
 
 // DisableBaseRoutes 禁用基础路由
 func (f *FlaskGo) DisableBaseRoutes() *FlaskGo {
-	core.BaseRoutesDisabled = true
+	f.flags.BaseRoutesDisabled = true
 	return f
 }
 
 // DisableSwagAutoCreate 禁用文档自动生成
 func (f *FlaskGo) DisableSwagAutoCreate() *FlaskGo {
-	core.SwaggerDisabled = true
+	f.flags.SwaggerDisabled = true
 	return f
 }
 
 // DisableRequestValidate 禁用请求体自动验证
 func (f *FlaskGo) DisableRequestValidate() *FlaskGo {
-	core.RequestValidateDisabled = true
+	f.flags.RequestValidateDisabled = true
 	return f
 }
 
 // DisableResponseValidate 禁用返回体自动验证
 func (f *FlaskGo) DisableResponseValidate() *FlaskGo {
-	core.ResponseValidateDisabled = true
+	f.flags.ResponseValidateDisabled = true
+	return f
+}
+
+// EnableQueryBinding 启用查询参数自动绑定
+// 启用后对于通过 Route.SetQueryParams 设置了查询参数的路由，会在执行路由函数前创建一个新的查询参数结构体实例,
+// 绑定并校验查询参数，之后可通过 Context.QueryParams 获取此结构体指针
+func (f *FlaskGo) EnableQueryBinding() *FlaskGo {
+	f.flags.QueryBindingEnabled = true
+	return f
+}
+
+// SetLanguage 设置校验错误信息的默认语言, 当请求头 Accept-Language 缺省或不受支持时使用
+//
+//	@param	lang	string	语言, 如: LanguageZH 或 LanguageEN, 不受支持的语言会被忽略
+func (f *FlaskGo) SetLanguage(lang string) *FlaskGo {
+	if _, found := f.service.translator.GetTranslator(lang); found {
+		f.service.language = strings.ToLower(lang)
+	}
+	return f
+}
+
+// EnableProblemDetails 启用 RFC 7807 错误消息, 启用后框架产生的全部错误响应(如: 参数校验错误、路由不存在、认证失败和服务器内部错误)
+// 均以 application/problem+json 格式返回, 其中参数校验错误保存于 invalid-params 字段, 文档中的 HTTPValidationError 模型同样替换为 ProblemDetails 模型
+func (f *FlaskGo) EnableProblemDetails() *FlaskGo {
+	f.flags.ProblemDetailsEnabled = true
+	return f
+}
+
+// SetDocsUrl 修改文档页面的路由, 缺省为 /docs, /redoc 和 /openapi.json, 参数为空时保持不变
+//
+//	@param	docsUrl		string	swagger 在线调试页面路由
+//	@param	redocUrl	string	redoc 文档页面路由
+//	@param	openapiUrl	string	openapi 文档路由
+func (f *FlaskGo) SetDocsUrl(docsUrl, redocUrl, openapiUrl string) *FlaskGo {
+	if docsUrl != "" {
+		f.docs.DocsUrl = docsUrl
+	}
+	if redocUrl != "" {
+		f.docs.RedocUrl = redocUrl
+	}
+	if openapiUrl != "" {
+		f.docs.OpenapiUrl = openapiUrl
+	}
+	return f
+}
+
+// UseCDNDocsAssets 文档页面改为从CDN加载静态文件, 缺省时 swagger 页面的静态文件由程序自身提供, 无需访问外网
+func (f *FlaskGo) UseCDNDocsAssets() *FlaskGo {
+	f.docs.UseCDNAssets()
+	return f
+}
+
+// SetDocsAssets 自定义文档页面静态文件的地址, 如内网的静态文件服务器, 参数为空时保持不变
+//
+//	@param	swaggerJsUrl	string	swagger-ui-bundle.js 地址
+//	@param	swaggerCssUrl	string	swagger-ui.css 地址
+//	@param	redocJsUrl		string	redoc.standalone.js 地址
+//	@param	faviconUrl		string	页面图标地址
+func (f *FlaskGo) SetDocsAssets(swaggerJsUrl, swaggerCssUrl, redocJsUrl, faviconUrl string) *FlaskGo {
+	if swaggerJsUrl != "" {
+		f.docs.SwaggerJsUrl = swaggerJsUrl
+	}
+	if swaggerCssUrl != "" {
+		f.docs.SwaggerCssUrl = swaggerCssUrl
+	}
+	if redocJsUrl != "" {
+		f.docs.RedocJsUrl = redocJsUrl
+	}
+	if faviconUrl != "" {
+		f.docs.SwaggerFaviconUrl, f.docs.RedocFaviconUrl = faviconUrl, faviconUrl
+	}
+	return f
+}
+
+// SetRedocFontsUrl 设置 redoc 页面的字体样式地址, 使用本地静态文件时缺省不加载外部字体, 从CDN加载时缺省为 Google Fonts
+//
+//	@param	fontsUrl	string	字体样式地址, 为空则使用浏览器缺省字体
+func (f *FlaskGo) SetRedocFontsUrl(fontsUrl string) *FlaskGo {
+	f.docs.RedocFontsUrl = fontsUrl
+	return f
+}
+
+// SetOpenApiVersion 修改 openapi 文档的版本, 缺省为 3.0.3; 3.1.0 版本的模型采用 JSON Schema 2020-12
+//
+//	@param	version	string	文档版本, openapi.Version303 或 openapi.Version310
+func (f *FlaskGo) SetOpenApiVersion(version string) *FlaskGo {
+	if version == openapi.Version303 || version == openapi.Version310 {
+		f.docs.Version = version
+	}
 	return f
 }
 
+// OverrideDependency 覆盖依赖项，作用于全部使用了此依赖项的路由(包括路由组依赖项和子依赖项)，而无需重新创建路由，通常用于测试;
+// 替换依赖项可以依赖原依赖项, 此时原依赖项本身仍会执行, 其返回值可通过 original.Value 获取
+//
+//	@param	original	*Dependency	原依赖项
+//	@param	replacement	*Dependency	替换依赖项, 若为nil则移除覆盖; 其返回值类型必须可赋值给原依赖项的返回值类型, 否则 panic,
+//										以免 DependencyValue 无法按原类型获取返回值
+func (f *FlaskGo) OverrideDependency(original, replacement *Dependency) *FlaskGo {
+	if original != nil && replacement != nil && !replacement.rtype.AssignableTo(original.rtype) {
+		panic(fmt.Sprintf("flaskgo: cannot override dependency '%s' of type %s with '%s' of type %s",
+			original.Name, original.rtype, replacement.Name, replacement.rtype))
+	}
+
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+
+	if f.dependencyOverrides == nil {
+		f.dependencyOverrides = make(map[*Dependency]*Dependency)
+	}
+	if replacement == nil {
+		delete(f.dependencyOverrides, original)
+	} else {
+		f.dependencyOverrides[original] = replacement
+	}
+	return f
+}
+
+// OverrideSecurity 覆盖认证依赖项，作用于全部使用了此认证方案(以 Security.SchemeName 区分)的路由，通常用于测试时跳过认证;
+// 文档中的认证方案不受影响
+//
+//	@param	original	Security	原认证依赖项
+//	@param	replacement	Security	替换认证依赖项, 若为nil则移除覆盖
+func (f *FlaskGo) OverrideSecurity(original, replacement Security) *FlaskGo {
+	if original == nil {
+		return f
+	}
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+
+	if f.securityOverrides == nil {
+		f.securityOverrides = make(map[string]Security)
+	}
+	if replacement == nil {
+		delete(f.securityOverrides, original.SchemeName())
+	} else {
+		f.securityOverrides[original.SchemeName()] = replacement
+	}
+	return f
+}
+
+// ResetOverrides 移除全部的依赖项覆盖和认证依赖项覆盖
+func (f *FlaskGo) ResetOverrides() *FlaskGo {
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+
+	f.dependencyOverrides = nil
+	f.securityOverrides = nil
+	return f
+}
+
+// dependency 获取实际执行的依赖项
+func (f *FlaskGo) dependency(d *Dependency) *Dependency {
+	f.overrideLock.RLock()
+	defer f.overrideLock.RUnlock()
+
+	if r, ok := f.dependencyOverrides[d]; ok {
+		return r
+	}
+	return d
+}
+
+// security 获取实际执行的认证依赖项
+func (f *FlaskGo) security(s Security) Security {
+	f.overrideLock.RLock()
+	defer f.overrideLock.RUnlock()
+
+	if len(f.securityOverrides) == 0 {
+		return s
+	}
+	if r, ok := f.securityOverrides[s.SchemeName()]; ok {
+		return r
+	}
+	return s
+}
+
 // DisableMultipleProcess 禁用多进程
 func (f *FlaskGo) DisableMultipleProcess() *FlaskGo {
-	core.MultipleProcessDisabled = true
+	f.flags.MultipleProcessDisabled = true
 	return f
 }
 
 // ShutdownWithTimeout 关机前最大等待时间
 func (f *FlaskGo) ShutdownWithTimeout() time.Duration {
-	return core.ShutdownWithTimeout * time.Second
+	return f.flags.ShutdownWithTimeout
 }
 
 // EnableDumpPID 启用PID存储
 func (f *FlaskGo) EnableDumpPID() *FlaskGo {
-	core.DumpPIDEnabled = true
+	f.flags.DumpPIDEnabled = true
 	return f
 }
 
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+	"github.com/Chendemo12/functools/helper"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// ProblemDetails RFC 7807 错误消息, 通过 FlaskGo.EnableProblemDetails 启用后,
+// 框架产生的全部错误响应均以 application/problem+json 格式返回
+type ProblemDetails struct {
+	Type          string             `json:"type"`                     // 错误类型URI, 缺省为 about:blank
+	Title         string             `json:"title"`                    // 错误摘要, 为状态码的标准描述
+	Status        int                `json:"status"`                   // 响应状态码
+	Detail        any                `json:"detail,omitempty"`         // 错误详情
+	Instance      string             `json:"instance,omitempty"`       // 发生错误的请求路由
+	InvalidParams []*ValidationError `json:"invalid-params,omitempty"` // 参数校验错误
+}
+
+func (p ProblemDetails) SchemaDesc() string { return "RFC 7807 Problem Details" }
+
+// newProblemDetails 由错误响应体构造 RFC 7807 错误消息
+//
+//	@param	c			*fiber.Ctx	请求上下文
+//	@param	statusCode	int			响应状态码
+//	@param	content		any			错误响应体, 如: *HTTPValidationError 或 {"detail": ...}
+func newProblemDetails(c *fiber.Ctx, statusCode int, content any) *ProblemDetails {
+	p := &ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Instance: c.Path(),
+	}
+
+	switch v := content.(type) {
+	case *HTTPValidationError:
+		p.InvalidParams = v.Detail
+	case fiber.Map:
+		p.Detail = v["detail"]
+	case map[string]string:
+		p.Detail = v["detail"]
+	default:
+		p.Detail = v
+	}
+
+	return p
+}
+
+// problemDetailsEnabled 请求所属的应用是否启用了 RFC 7807 错误消息
+func problemDetailsEnabled(c *fiber.Ctx) bool {
+	app := appFromCtx(c)
+	return app != nil && app.flags.ProblemDetailsEnabled
+}
+
+// writeErrorContent 写入错误响应, 若启用了 RFC 7807 错误消息则转换为 application/problem+json 格式
+//
+//	@param	c			*fiber.Ctx	请求上下文
+//	@param	statusCode	int			响应状态码
+//	@param	content		any			错误响应体
+func writeErrorContent(c *fiber.Ctx, statusCode int, content any) error {
+	if !problemDetailsEnabled(c) {
+		return c.Status(statusCode).JSON(content)
+	}
+
+	bs, err := helper.DefaultJsonMarshal(newProblemDetails(c, statusCode, content))
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, string(openapi.MIMEApplicationProblemJSON))
+	return c.Status(statusCode).Send(bs)
+}
+
+// writeErrorDetail 写入 {"detail": detail} 错误响应
+func writeErrorDetail(c *fiber.Ctx, statusCode int, detail any) error {
+	return writeErrorContent(c, statusCode, fiber.Map{"detail": detail})
+}
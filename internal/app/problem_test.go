@@ -0,0 +1,95 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+)
+
+func newProblemApp() *FlaskGo {
+	app := NewFlaskGo("test", "1.0.0", false, nil).EnableProblemDetails()
+	router := APIRouter("/api", nil)
+	router.POST("/user", &testUser{}, &testUser{}, "创建用户", func(c *Context) *Response {
+		user := &testUser{}
+		if resp := c.ShouldBindJSON(user); resp != nil {
+			return resp
+		}
+		return c.OKResponse(user)
+	})
+	router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+		return c.StringResponse("ok")
+	}).AddSecurity(APIKeyHeader("X-Token", nil))
+	router.GET("/boom", godantic.String, "服务器错误", func(c *Context) *Response {
+		panic("boom")
+	})
+	app.IncludeRouter(router)
+	return app
+}
+
+func TestProblemDetails(t *testing.T) {
+	client := NewTestClient(newProblemApp())
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		detail   bool
+		invalids []string
+	}{
+		{name: "not found", method: http.MethodGet, path: "/api/missing", status: http.StatusNotFound, detail: true},
+		{name: "method not allowed", method: http.MethodDelete, path: "/api/user", status: http.StatusMethodNotAllowed, detail: true},
+		{
+			name: "validation", method: http.MethodPost, path: "/api/user", body: `{"age": 200}`,
+			status: http.StatusUnprocessableEntity, invalids: []string{"Name", "Age"},
+		},
+		{name: "unauthorized", method: http.MethodGet, path: "/api/me", status: http.StatusUnauthorized, detail: true},
+		{name: "panic", method: http.MethodGet, path: "/api/boom", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := client.Do(req).AssertStatus(t, tt.status)
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, string(openapi.MIMEApplicationProblemJSON)) {
+				t.Fatalf("expected content type %s, got %q: %s", openapi.MIMEApplicationProblemJSON, ct, resp.Body)
+			}
+
+			problem := &ProblemDetails{}
+			if err := resp.JSON(problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Type != "about:blank" || problem.Status != tt.status ||
+				problem.Title != http.StatusText(tt.status) || problem.Instance != tt.path {
+				t.Errorf("unexpected problem members: %s", resp.Body)
+			}
+			if tt.detail && problem.Detail == nil {
+				t.Errorf("expected a detail member: %s", resp.Body)
+			}
+
+			locs := make([]string, 0)
+			for _, ve := range problem.InvalidParams {
+				locs = append(locs, ve.Loc[len(ve.Loc)-1])
+			}
+			if strings.Join(locs, ",") != strings.Join(tt.invalids, ",") {
+				t.Errorf("expected invalid-params %v, got %s", tt.invalids, resp.Body)
+			}
+		})
+	}
+}
+
+func TestProblemDetailsOpenAPI(t *testing.T) {
+	bs, err := newProblemApp().OpenAPI()
+	if err != nil {
+		t.Fatalf("generate openapi: %v", err)
+	}
+	doc := string(bs)
+	if !strings.Contains(doc, string(openapi.MIMEApplicationProblemJSON)) || strings.Contains(doc, "HTTPValidationError") {
+		t.Errorf("expected the error responses to use the problem schema, got %s", doc)
+	}
+}
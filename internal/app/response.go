@@ -18,6 +18,7 @@ const (
 	ErrResponseType
 	HtmlResponseType
 	AdvancedResponseType
+	ExceptionResponseType
 )
 
 const ( // error message
@@ -26,8 +27,6 @@ const ( // error message
 	ModelNotString = "Value is not a string"
 )
 
-var responseHeaders []*ResponseHeader
-
 // ResponseHeader 自定义响应头
 type ResponseHeader struct {
 	Key   string `json:"key" Description:"Key" binding:"required"`
@@ -62,6 +61,11 @@ func (v ValidationError) String() string {
 
 func (v ValidationError) SchemaDesc() string { return "Validation Error" }
 
+// validationErrorFrom 将 godantic 的校验错误转换为接口错误类型
+func validationErrorFrom(e *godantic.ValidationError) *ValidationError {
+	return &ValidationError{Ctx: e.Ctx, Msg: e.Msg, Type: e.Type, Loc: e.Loc}
+}
+
 type HTTPValidationError struct {
 	Detail []*ValidationError `json:"detail" Description:"Detail" binding:"required"`
 }
@@ -85,6 +89,15 @@ func ValidationErrorResponse(ves ...*ValidationError) *Response {
 	}
 }
 
+// ResponseValidationErrorResponse 返回值校验错误，此错误由服务端产生，因此状态码为500
+func ResponseValidationErrorResponse(ves ...*ValidationError) *Response {
+	return &Response{
+		StatusCode: http.StatusInternalServerError,
+		Content:    &HTTPValidationError{Detail: ves},
+		Type:       ErrResponseType,
+	}
+}
+
 // AnyResponse 自定义响应体,响应体可是任意类型
 //
 //	@param	statusCode	int		响应状态码
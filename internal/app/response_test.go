@@ -0,0 +1,110 @@
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+type testDevice struct {
+	godantic.BaseModel
+	Name  string   `json:"name" validate:"required"`
+	Mode  string   `json:"mode" oneof:"prod dev"`
+	Port  int      `json:"port" gte:"1" lte:"65535"`
+	Code  string   `json:"code" pattern:"^[A-Z]{3}$"`
+	Tags  []string `json:"tags" lte:"2"`
+	Owner *struct {
+		Name string `json:"name" validate:"required"`
+	} `json:"owner"`
+}
+
+type testDeviceError struct {
+	godantic.BaseModel
+	Reason string `json:"reason" validate:"required"`
+}
+
+func TestResponseValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content any
+		status  int
+		model   godantic.SchemaIface
+		loc     []string
+		msg     string
+	}{
+		{name: "optional fields zero", content: &testDevice{Name: "x"}, status: http.StatusOK},
+		{name: "all fields valid", content: &testDevice{Name: "x", Mode: "dev", Port: 80, Code: "ABC", Tags: []string{"a"}}, status: http.StatusOK},
+		{name: "value instead of pointer", content: testDevice{Name: "x"}, status: http.StatusOK},
+		{name: "required missing", content: &testDevice{Mode: "dev"}, loc: []string{"response", "name"}, msg: godantic.FieldRequired},
+		{name: "enum", content: &testDevice{Name: "x", Mode: "test"}, loc: []string{"response", "mode"}, msg: godantic.ValueNotInEnum},
+		{name: "less than", content: &testDevice{Name: "x", Port: -1}, loc: []string{"response", "port"}, msg: godantic.ValueLessThan},
+		{name: "greater than", content: &testDevice{Name: "x", Port: 65536}, loc: []string{"response", "port"}, msg: godantic.ValueGreaterThan},
+		{name: "pattern", content: &testDevice{Name: "x", Code: "abc"}, loc: []string{"response", "code"}, msg: godantic.StringNotMatch},
+		{name: "array length", content: &testDevice{Name: "x", Tags: []string{"a", "b", "c"}}, loc: []string{"response", "tags"}, msg: godantic.ValueGreaterThan},
+		{
+			name: "nested required", content: &testDevice{Name: "x", Owner: &struct {
+				Name string `json:"name" validate:"required"`
+			}{}}, loc: []string{"response", "owner", "name"}, msg: godantic.FieldRequired,
+		},
+		{name: "type mismatch", content: map[string]any{"name": "x"}, loc: []string{"response"}, msg: godantic.ValueTypeMismatch},
+		{name: "list", content: []*testDevice{{Name: "a"}, {Name: "b", Mode: "dev"}}, model: godantic.List(&testDevice{}), status: http.StatusOK},
+		{
+			name: "list element", content: []*testDevice{{Name: "a"}, {Name: "b", Mode: "test"}}, model: godantic.List(&testDevice{}),
+			loc: []string{"response", "1", "mode"}, msg: godantic.ValueNotInEnum,
+		},
+		{name: "field", content: "ok", model: godantic.String, status: http.StatusOK},
+		{name: "field type mismatch", content: 1, model: godantic.String, loc: []string{"response"}, msg: godantic.ValueTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := tt.model
+			if model == nil {
+				model = &testDevice{}
+			}
+			app := NewFlaskGo("test", "1.0.0", false, nil)
+			router := APIRouter("/api", nil)
+			router.GET("/device", model, "设备", func(c *Context) *Response { return c.OKResponse(tt.content) })
+			app.IncludeRouter(router)
+
+			status := tt.status
+			if tt.msg != "" {
+				status = http.StatusInternalServerError
+			}
+			resp := NewTestClient(app).Get("/api/device", nil).AssertStatus(t, status)
+			if tt.msg == "" {
+				return
+			}
+
+			body := &HTTPValidationError{}
+			if err := resp.JSON(body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Detail) != 1 || body.Detail[0].Msg != tt.msg || !reflect.DeepEqual(body.Detail[0].Loc, tt.loc) {
+				t.Errorf("expected %q at %v, got %s", tt.msg, tt.loc, resp.Body)
+			}
+		})
+	}
+}
+
+func TestResponseValidationByStatusCode(t *testing.T) {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	router.GET("/device", &testDevice{}, "设备", func(c *Context) *Response {
+		if c.ec.Query("missing") != "" {
+			return c.JSONResponse(http.StatusNotFound, &testDeviceError{})
+		}
+		return c.JSONResponse(http.StatusNotFound, &testDeviceError{Reason: "not found"})
+	}).AddResponse(http.StatusNotFound, &testDeviceError{}, "设备不存在")
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	// 404 的返回值依据 testDeviceError 而非 testDevice 校验
+	client.Get("/api/device", nil).AssertStatus(t, http.StatusNotFound)
+	client.Get("/api/device?missing=1", nil).AssertStatus(t, http.StatusInternalServerError)
+
+	app.DisableResponseValidate()
+	client.Get("/api/device?missing=1", nil).AssertStatus(t, http.StatusNotFound)
+}
@@ -3,7 +3,9 @@ package app
 import (
 	"github.com/Chendemo12/flaskgo/internal/constant"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/gofiber/fiber/v2"
+	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
@@ -12,14 +14,6 @@ import (
 // RouteSeparator 路由分隔符，用于分割路由方法和路径
 const RouteSeparator = "|_0#0_|"
 
-var ( // 记录创建的路由对象，用于其后的请求和响应校验
-	MethodGetRoutes    = map[string]*Route{}
-	MethodPostRoutes   = map[string]*Route{}
-	MethodPatchRoutes  = map[string]*Route{}
-	MethodPutRoutes    = map[string]*Route{}
-	MethodDeleteRoutes = map[string]*Route{}
-)
-
 // APIRouter 创建一个路由组
 func APIRouter(prefix string, tags []string) *Router {
 	fgr := &Router{
@@ -31,25 +25,49 @@ func APIRouter(prefix string, tags []string) *Router {
 	return fgr
 }
 
+// RouteResponse 路由的附加响应，用于声明除 200 和 422 以外的响应，如: 201, 404 和 409
+type RouteResponse struct {
+	Model       godantic.SchemaIface // 响应模型, 允许为nil
+	Description string               // 响应说明, 缺省为状态码的标准描述
+	StatusCode  int                  // 响应状态码
+}
+
 // Route 一个完整的路由对象，此对象会在程序启动时生成swagger文档
 // 其中相对路径Path不能重复，否则后者会覆盖前者
 type Route struct {
-	RequestModel  godantic.SchemaIface // 请求体模型, 此模型恒 != nil
-	ResponseModel godantic.SchemaIface // 响应模型,路由参数, 此模型恒 != nil
-	RelativePath  string               // 请求相对路由, 必定以/开头,路由参数
-	Method        string               // 请求方法
-	Summary       string               // 路由摘要,路由参数
-	Description   string               // 路由详细描述
-	Tags          []string             // route tags
-	PathFields    []*godantic.QModel   // 路径参数
-	QueryFields   []*godantic.QModel   // 查询参数
-	Handlers      []fiber.Handler      // 路由处理钩子
+	RequestModel  godantic.SchemaIface    // 请求体模型, 此模型恒 != nil
+	ResponseModel godantic.SchemaIface    // 响应模型,路由参数, 此模型恒 != nil
+	RelativePath  string                  // 请求相对路由, 必定以/开头,路由参数
+	Method        string                  // 请求方法
+	Summary       string                  // 路由摘要,路由参数
+	Description   string                  // 路由详细描述
+	Tags          []string                // route tags
+	PathFields    []*godantic.QModel      // 路径参数
+	QueryFields   []*godantic.QModel      // 查询参数
+	QueryModel    godantic.QueryParameter // 查询参数模型, 用于查询参数自动绑定
+	HeaderFields  []*godantic.QModel      // 请求头参数
+	CookieFields  []*godantic.QModel      // cookie参数
+	ContentTypes  []string                // 请求体支持的数据格式, 缺省时为 application/json
+	Responses     []*RouteResponse        // 附加响应
+	Securities    []Security              // 认证依赖项
+	Scopes        []string                // 路由所需的授权范围
+	Handlers      []fiber.Handler         // 路由处理钩子
 	Dependencies  []HandlerFunc
+	DependsOn     []*Dependency // 依赖项, 包含路由组的依赖项, 于 Dependencies 之后执行
 	deprecated    bool // 是否禁用此路由
 }
 
 func (f *Route) LowerMethod() string { return strings.ToLower(f.Method) }
 
+// mimeTypes 请求体支持的数据格式, 用于生成文档
+func (f *Route) mimeTypes() []openapi.ApplicationMIMEType {
+	m := make([]openapi.ApplicationMIMEType, len(f.ContentTypes))
+	for i, ct := range f.ContentTypes {
+		m[i] = openapi.ApplicationMIMEType(ct)
+	}
+	return m
+}
+
 // Deprecate 禁用路由
 func (f *Route) Deprecate() *Route {
 	f.deprecated = true
@@ -65,6 +83,35 @@ func (f *Route) AddDependency(fcs ...HandlerFunc) *Route {
 	return f
 }
 
+// Depends 添加依赖项，依赖项的返回值保存于 Context, 可通过 DependencyValue 按类型获取
+//	@param	deps	*Dependency	依赖项
+func (f *Route) Depends(deps ...*Dependency) *Route {
+	f.DependsOn = prependDependencies(nil, append(f.DependsOn, deps...))
+	return f
+}
+
+// AddSecurity 添加认证依赖项，认证依赖项会先于其他依赖项执行，且其认证方案会自动注册到文档中
+//	@param	ss	Security	认证依赖项, 如: APIKeyHeader, HTTPBasic, HTTPBearer 和 OAuth2PasswordBearer
+func (f *Route) AddSecurity(ss ...Security) *Route {
+	for _, s := range ss {
+		if s == nil {
+			continue
+		}
+		f.Securities = append(f.Securities, s)
+	}
+	return f
+}
+
+// SetScopes 设置路由所需的授权范围, 由 JWTBearer 等认证依赖项校验; 授权范围显示在文档的 security 中,
+// 但 3.0.3 版本的文档仅允许 oauth2 和 openIdConnect 声明授权范围, 此时 JWTBearer 需设置 TokenUrl 以 oauth2 方式描述,
+// 3.1.0 版本的文档则对全部认证方式声明授权范围
+//
+//	@param	scopes	[]string	授权范围
+func (f *Route) SetScopes(scopes ...string) *Route {
+	f.Scopes = scopes
+	return f
+}
+
 // SetDescription 设置一个路由的详细描述信息
 //	@param	Description	string	详细描述信息
 func (f *Route) SetDescription(description string) *Route {
@@ -72,15 +119,105 @@ func (f *Route) SetDescription(description string) *Route {
 	return f
 }
 
-// SetQueryParams 设置查询参数,此空struct的每一个字段都将作为一个单独的查询参数
+// SetQueryParams 设置查询参数,此空struct的每一个字段都将作为一个单独的查询参数,
+// 对于嵌入了 godantic.QueryModel 的结构体，需传入结构体指针
 //	@param	m	any	查询参数对象
 func (f *Route) SetQueryParams(m godantic.QueryParameter) *Route {
 	if m != nil {
-		f.QueryFields = m.Fields() // 转换为内部模型
+		f.QueryFields = godantic.ReflectQueryModel(m) // 转换为内部模型
+		f.QueryModel = m
+	}
+	return f
+}
+
+// SetHeaderParams 设置请求头参数,此struct的每一个字段都将作为一个单独的请求头参数, 参数名以json标签为准,
+// 对于嵌入了 godantic.HeaderModel 的结构体，需传入结构体指针
+//	@param	m	any	请求头参数对象
+func (f *Route) SetHeaderParams(m godantic.QueryParameter) *Route {
+	if m != nil {
+		f.HeaderFields = paramsIn(godantic.ReflectQueryModel(m), godantic.InHeader)
+	}
+	return f
+}
+
+// SetCookieParams 设置cookie参数,此struct的每一个字段都将作为一个单独的cookie参数, 参数名以json标签为准,
+// 对于嵌入了 godantic.CookieModel 的结构体，需传入结构体指针
+//	@param	m	any	cookie参数对象
+func (f *Route) SetCookieParams(m godantic.QueryParameter) *Route {
+	if m != nil {
+		f.CookieFields = paramsIn(godantic.ReflectQueryModel(m), godantic.InCookie)
+	}
+	return f
+}
+
+// SetPathParams 设置路径参数的类型, 此struct中与路径参数同名(以json标签为准)的字段将作为路径参数的类型定义,
+// 未定义类型的路径参数以字符串处理
+//	@param	m	any	路径参数对象
+func (f *Route) SetPathParams(m godantic.QueryParameter) *Route {
+	if m == nil {
+		return f
+	}
+
+	for _, qm := range godantic.ReflectQueryModel(m) {
+		for i := 0; i < len(f.PathFields); i++ {
+			if f.PathFields[i].SchemaName() == qm.SchemaName() {
+				// 保留路径中定义的必选属性
+				f.PathFields[i].Tag = f.PathFields[i].Tag + " " + qm.Tag
+				f.PathFields[i].RType = qm.RType
+				f.PathFields[i].OType = qm.OType
+			}
+		}
 	}
 	return f
 }
 
+// SetContentTypes 设置请求体支持的数据格式, 如: application/json 和 application/x-www-form-urlencoded，
+// 设置后请求体会依据请求头 Content-Type 自动绑定到 Context.RequestBody, 不支持的数据格式返回415错误
+//	@param	contentTypes	[]string	请求体数据格式
+func (f *Route) SetContentTypes(contentTypes ...string) *Route {
+	f.ContentTypes = contentTypes
+	return f
+}
+
+// AddResponse 添加一个附加响应，用于在文档中声明其他的响应状态码，返回值校验时依据状态码选择对应的响应模型,
+// 若状态码已存在则覆盖，对于 200 状态码则替换路由的 ResponseModel
+//	@param	statusCode	int						响应状态码
+//	@param	model		godantic.SchemaIface	响应模型, 允许为nil
+//	@param	description	string					响应说明, 缺省为状态码的标准描述
+func (f *Route) AddResponse(statusCode int, model godantic.SchemaIface, description string) *Route {
+	registerModel(model)
+	if statusCode == http.StatusOK {
+		f.ResponseModel = model
+	}
+	if description == "" {
+		description = http.StatusText(statusCode)
+	}
+
+	resp := &RouteResponse{Model: model, Description: description, StatusCode: statusCode}
+	for i := 0; i < len(f.Responses); i++ {
+		if f.Responses[i].StatusCode == statusCode {
+			f.Responses[i] = resp
+			return f
+		}
+	}
+	f.Responses = append(f.Responses, resp)
+	return f
+}
+
+// ResponseModelFor 获取与状态码相匹配的响应模型, 对于未声明的 2xx 状态码则返回 ResponseModel
+//	@param	statusCode	int	响应状态码
+func (f *Route) ResponseModelFor(statusCode int) godantic.SchemaIface {
+	for _, resp := range f.Responses {
+		if resp.StatusCode == statusCode {
+			return resp.Model
+		}
+	}
+	if isSuccessStatus(statusCode) {
+		return f.ResponseModel
+	}
+	return nil
+}
+
 // SetRequestModel 设置请求体对象,此model应为一个空struct实例,而非指针类型,且仅"GET",http.MethodDelete有效
 //	@param	m	any	请求体对象
 func (f *Route) SetRequestModel(m godantic.SchemaIface) *Route {
@@ -97,6 +234,7 @@ func (f *Route) Path(prefix string) string { return CombinePath(prefix, f.Relati
 // Router 一个独立的路由组，Prefix路由组前缀，其内部的子路由均包含此前缀
 type Router struct {
 	routes     map[string]*Route
+	dependsOn  []*Dependency // 路由组依赖项
 	Prefix     string
 	Tags       []string
 	deprecated bool
@@ -117,10 +255,21 @@ func (f *Router) Activate() *Router {
 	return f
 }
 
+// Depends 添加路由组依赖项，作用于路由组内的全部路由(包括之后添加的路由和挂载的子路由组), 且先于路由自身的依赖项执行
+//	@param	deps	*Dependency	依赖项
+func (f *Router) Depends(deps ...*Dependency) *Router {
+	f.dependsOn = prependDependencies(nil, append(f.dependsOn, deps...))
+	for _, route := range f.routes {
+		route.DependsOn = prependDependencies(deps, route.DependsOn)
+	}
+	return f
+}
+
 // IncludeRouter 挂载一个子路由组,目前仅支持在子路由组初始化后添加
 //	@param	router	*Router	子路由组
 func (f *Router) IncludeRouter(router *Router) *Router {
 	for _, route := range router.Routes() {
+		route.DependsOn = prependDependencies(f.dependsOn, route.DependsOn)
 		route.RelativePath = CombinePath(router.Prefix, route.RelativePath)
 		f.routes[route.RelativePath+RouteSeparator+route.Method] = route // 允许地址相同,方法不同的路由
 
@@ -131,36 +280,40 @@ func (f *Router) IncludeRouter(router *Router) *Router {
 
 func (f *Router) method(
 	method, relativePath, summary string,
-	queryModel godantic.QueryParameter, requestModel, responseModel godantic.SchemaIface,
+	requestModel, responseModel godantic.SchemaIface,
 	handler HandlerFunc,
 	additions []any,
 ) *Route {
-	if requestModel != nil {
-		meta := godantic.GetMetadataFactory().Reflect(requestModel)
-		meta.SetDesc(requestModel.SchemaDesc())
-		godantic.SaveMetadata(meta)
-		requestModel.SetId(meta.Id())
-	}
-	if responseModel != nil {
-		meta := godantic.GetMetadataFactory().Reflect(responseModel)
-		meta.SetDesc(responseModel.SchemaDesc())
-		responseModel.SetId(meta.Id())
-		godantic.SaveMetadata(meta)
-	}
+	registerModel(requestModel)
+	registerModel(responseModel)
 	// 路由处理函数，默认仅一个
 	handlers := []fiber.Handler{routeHandler(handler)}
 	deprecated := false // 是否禁用此路由
+	dependsOn := prependDependencies(f.dependsOn, nil)
 
+	paramModels := make([]godantic.QueryParameter, 0)
 	for _, adt := range additions {
-		rt := reflect.TypeOf(adt)
-		switch rt.Kind() {
-		case reflect.String:
-			if adt == "deprecated" {
-				deprecated = true
+		switch v := adt.(type) {
+		case godantic.QueryParameter: // 发现查询参数、请求头参数或cookie参数模型
+			paramModels = append(paramModels, v)
+		case *Dependency: // 发现依赖项
+			if v == nil {
+				panic(unsupportedAddition(method, relativePath, adt))
+			}
+			dependsOn = prependDependencies(dependsOn, []*Dependency{v})
+		case HandlerFunc:
+			handlers = append(handlers, routeHandler(v))
+		case string:
+			if v != "deprecated" {
+				panic(unsupportedAddition(method, relativePath, adt))
 			}
-		case reflect.Func:
-			// 发现fiber.handler
-			handlers = append(handlers, routeHandler(adt.(HandlerFunc)))
+			deprecated = true
+		default:
+			qm, ok := addressableQueryParameter(adt)
+			if !ok {
+				panic(unsupportedAddition(method, relativePath, adt))
+			}
+			paramModels = append(paramModels, qm)
 		}
 	}
 
@@ -178,18 +331,30 @@ func (f *Router) method(
 		RelativePath:  relativePath,
 		PathFields:    make([]*godantic.QModel, 0), // 路径参数
 		QueryFields:   make([]*godantic.QModel, 0), // 查询参数
+		HeaderFields:  make([]*godantic.QModel, 0), // 请求头参数
+		CookieFields:  make([]*godantic.QModel, 0), // cookie参数
 		RequestModel:  requestModel,                // 请求体
 		ResponseModel: responseModel,               // 响应体
 		Summary:       summary,
 		Handlers:      handlers,
 		Dependencies:  make([]HandlerFunc, 0),
+		DependsOn:     dependsOn,
 		Tags:          f.Tags,
 		Description:   method + " " + summary,
 		deprecated:    deprecated,
 	}
 
-	if queryModel != nil {
-		route.QueryFields = append(route.QueryFields, queryModel.Fields()...)
+	for _, qm := range paramModels {
+		// 嵌入了 HeaderModel 或 CookieModel 的结构体分别作为请求头参数和cookie参数
+		switch godantic.ParameterLocation(qm) {
+		case godantic.InHeader:
+			route.SetHeaderParams(qm)
+		case godantic.InCookie:
+			route.SetCookieParams(qm)
+		default:
+			route.QueryFields = paramsIn(godantic.ReflectQueryModel(qm), godantic.InQuery)
+			route.QueryModel = qm
+		}
 	}
 
 	// 生成路径参数
@@ -214,17 +379,17 @@ func (f *Router) method(
 // GET http get method
 //	@param	path			string					相对路径,必须以"/"开头
 //	@param	summary			string					路由摘要信息
-//	@param	queryModel		godantic.QueryParameter	查询参数，仅支持struct类型
 //	@param	responseModel	godantic.SchemaIface	响应体对象,	此model应为一个空struct实例,而非指针类型
 //	@param	handler			[]HandlerFunc			路由处理方法
-//	@param	addition		any						附加参数，如："deprecated"用于禁用此路由
+//	@param	addition		any						附加参数，如："deprecated"用于禁用此路由,
+//													godantic.QueryParameter 用于定义查询参数，仅支持struct类型, 嵌入 HeaderModel 或 CookieModel 时作为请求头参数或cookie参数,
+//													*Dependency 为依赖项, HandlerFunc 为附加的处理方法; 其他类型(包括nil)会导致 panic
 func (f *Router) GET(
 	path string, responseModel godantic.SchemaIface, summary string, handler HandlerFunc, addition ...any,
 ) *Route {
-	// 对于查询参数仅允许struct类型
 	return f.method(
 		http.MethodGet, path, summary,
-		nil, nil, responseModel,
+		nil, responseModel,
 		handler, addition,
 	)
 }
@@ -234,14 +399,15 @@ func (f *Router) GET(
 //	@param	summary			string					路由摘要信息
 //	@param	responseModel	godantic.SchemaIface	响应体对象,	此model应为一个空struct实例,而非指针类型
 //	@param	handler			[]HandlerFunc			路由处理方法
-//	@param	addition		any						附加参数
+//	@param	addition		any						附加参数，如："deprecated"用于禁用此路由,
+//													godantic.QueryParameter 用于定义查询参数，仅支持struct类型, 嵌入 HeaderModel 或 CookieModel 时作为请求头参数或cookie参数,
+//													*Dependency 为依赖项, HandlerFunc 为附加的处理方法; 其他类型(包括nil)会导致 panic
 func (f *Router) DELETE(
 	path string, responseModel godantic.SchemaIface, summary string, handler HandlerFunc, addition ...any,
 ) *Route {
-	// 对于查询参数仅允许struct类型
 	return f.method(
 		http.MethodDelete, path, summary,
-		nil, nil, responseModel,
+		nil, responseModel,
 		handler, addition,
 	)
 }
@@ -252,7 +418,9 @@ func (f *Router) DELETE(
 //	@param	requestModel	godantic.SchemaIface	请求体对象,	此model应为一个空struct实例,而非指针类型
 //	@param	responseModel	godantic.SchemaIface	响应体对象,	此model应为一个空struct实例,而非指针类型
 //	@param	handler			[]HandlerFunc			路由处理方法
-//	@param	addition		any						附加参数，如："deprecated"用于禁用此路由
+//	@param	addition		any						附加参数，如："deprecated"用于禁用此路由,
+//													godantic.QueryParameter 用于定义查询参数，仅支持struct类型, 嵌入 HeaderModel 或 CookieModel 时作为请求头参数或cookie参数,
+//													*Dependency 为依赖项, HandlerFunc 为附加的处理方法; 其他类型(包括nil)会导致 panic
 func (f *Router) POST(
 	path string,
 	requestModel, responseModel godantic.SchemaIface,
@@ -262,7 +430,7 @@ func (f *Router) POST(
 ) *Route {
 	return f.method(
 		http.MethodPost, path, summary,
-		nil, requestModel, responseModel,
+		requestModel, responseModel,
 		handler, addition,
 	)
 }
@@ -277,7 +445,7 @@ func (f *Router) PATCH(
 ) *Route {
 	return f.method(
 		http.MethodPatch, path, summary,
-		nil, requestModel, responseModel,
+		requestModel, responseModel,
 		handler, addition,
 	)
 }
@@ -292,11 +460,40 @@ func (f *Router) PUT(
 ) *Route {
 	return f.method(
 		http.MethodPut, path, summary,
-		nil, requestModel, responseModel,
+		requestModel, responseModel,
 		handler, addition,
 	)
 }
 
+// addressableQueryParameter 以结构体实例(而非指针)定义的参数模型, 其指针实现了 godantic.QueryParameter 时转换为指针
+func addressableQueryParameter(adt any) (godantic.QueryParameter, bool) {
+	rv := reflect.ValueOf(adt)
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+	qm, ok := ptr.Interface().(godantic.QueryParameter)
+	return qm, ok
+}
+
+// unsupportedAddition 路由的附加参数无法识别, 通常为传入了nil或错误的类型
+func unsupportedAddition(method, relativePath string, adt any) string {
+	return fmt.Sprintf(
+		"flaskgo: unsupported addition %#v (%T) for route '%s %s', "+
+			"expected \"deprecated\", godantic.QueryParameter, *Dependency or HandlerFunc",
+		adt, adt, method, relativePath,
+	)
+}
+
+// paramsIn 修改参数的位置
+func paramsIn(fields []*godantic.QModel, in string) []*godantic.QModel {
+	for i := 0; i < len(fields); i++ {
+		fields[i].In = in
+	}
+	return fields
+}
+
 // CombinePath 合并路由
 //	@param	prefix	string	路由前缀
 //	@param	path	string	路由
@@ -314,50 +511,5 @@ func CombinePath(prefix, path string) string {
 	return prefix + path
 }
 
-// GetRoute 查询自定义路由
-//	@param	method	string	请求方法
-//	@param	path	string	请求路由
-//	@return	*Route 自定义路由对象
-func GetRoute(method string, path string) (route *Route) {
-	switch method {
-	case http.MethodGet:
-		r, ok := MethodGetRoutes[path]
-		if !ok {
-			route = nil
-		} else {
-			route = r
-		}
-	case http.MethodPut:
-		r, ok := MethodPutRoutes[path]
-		if !ok {
-			route = nil
-		} else {
-			route = r
-		}
-	case http.MethodPatch:
-		r, ok := MethodPatchRoutes[path]
-		if !ok {
-			route = nil
-		} else {
-			route = r
-		}
-	case http.MethodDelete:
-		r, ok := MethodDeleteRoutes[path]
-		if !ok {
-			route = nil
-		} else {
-			route = r
-		}
-	case http.MethodPost:
-		r, ok := MethodPostRoutes[path]
-		if !ok {
-			route = nil
-		} else {
-			route = r
-		}
-	default:
-		route = nil
-	}
-
-	return
-}
+// registerModel 反射并保存模型的元信息
+func registerModel(model godantic.SchemaIface) { godantic.RegisterModel(model) }
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+func TestRouterQueryModelValue(t *testing.T) {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	route := router.GET("/user", godantic.String, "获取用户", func(c *Context) *Response {
+		return c.StringResponse(c.QueryFields["name"])
+	}, testUserQuery{}) // 结构体实例而非指针
+	app.IncludeRouter(router)
+
+	if len(route.QueryFields) != 1 || godantic.QueryJsonName(route.QueryFields[0].Tag, route.QueryFields[0].Title) != "name" {
+		t.Fatalf("expected query parameter 'name', got %v", route.QueryFields)
+	}
+
+	client := NewTestClient(app)
+	client.Get("/api/user", nil).AssertStatus(t, http.StatusUnprocessableEntity)
+	if resp := client.Get("/api/user", url.Values{"name": {"lee"}}).AssertStatus(t, http.StatusOK); resp.Text() != "lee" {
+		t.Errorf("expected body 'lee', got %q", resp.Text())
+	}
+}
+
+func TestRouterUnsupportedAddition(t *testing.T) {
+	tests := map[string]any{
+		"nil":            nil,
+		"nil dependency": (*Dependency)(nil),
+		"unknown string": "deprecate",
+		"fiber handler":  func(c *Context) error { return nil },
+		"struct":         testUser{},
+	}
+
+	for name, addition := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("expected a panic")
+				}
+				if msg, _ := r.(string); !strings.Contains(msg, "GET /user") {
+					t.Errorf("expected the panic to name the route, got %v", r)
+				}
+			}()
+			APIRouter("/api", nil).GET("/user", godantic.String, "获取用户", func(c *Context) *Response {
+				return c.StringResponse("ok")
+			}, addition)
+		})
+	}
+}
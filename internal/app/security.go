@@ -0,0 +1,294 @@
+package app
+
+import (
+	"encoding/base64"
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+const NotAuthenticated = "Not authenticated"
+
+// Security 认证依赖项, 通过 Route.AddSecurity 添加到路由, 其认证方案会自动注册到文档的 components.securitySchemes 中
+type Security interface {
+	// SchemeName 认证方案名称, 同一个应用内应保持唯一
+	SchemeName() string
+	// Scheme 认证方案文档
+	Scheme() *openapi.SecurityScheme
+	// Authenticate 认证依赖项, 认证通过时返回nil
+	Authenticate(c *Context) *Response
+}
+
+// HTTPBasicCredentials HTTP Basic 认证凭证
+type HTTPBasicCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// HTTPAuthorizationCredentials HTTP Authorization 请求头凭证, 如: Bearer xxx
+type HTTPAuthorizationCredentials struct {
+	Scheme      string `json:"scheme"`
+	Credentials string `json:"credentials"`
+}
+
+// OAuth2PasswordRequestForm OAuth2 密码模式的令牌请求表单, 可作为令牌路由的请求体
+//
+//	router.POST("/token", &flaskgo.OAuth2PasswordRequestForm{}, &Token{}, "获取令牌", login).
+//		SetContentTypes(fiber.MIMEApplicationForm)
+type OAuth2PasswordRequestForm struct {
+	godantic.BaseModel
+	GrantType    string `json:"grant_type" description:"授权类型" oneof:"password"`
+	Username     string `json:"username" description:"用户名" validate:"required"`
+	Password     string `json:"password" description:"密码" validate:"required"`
+	Scope        string `json:"scope" description:"授权范围, 以空格分隔"`
+	ClientId     string `json:"client_id" description:"客户端ID"`
+	ClientSecret string `json:"client_secret" description:"客户端密钥"`
+}
+
+func (f *OAuth2PasswordRequestForm) SchemaDesc() string { return "OAuth2 密码模式令牌请求" }
+
+// Scopes 请求的授权范围
+func (f *OAuth2PasswordRequestForm) Scopes() []string { return strings.Fields(f.Scope) }
+
+// ------------------------------------- api key ---------------------------------------
+
+// APIKey 通过请求头, 查询参数或cookie传递的API密钥认证
+type APIKey struct {
+	verify      func(c *Context, key string) *Response
+	Name        string           // 参数名称
+	In          openapi.APIKeyIn // 参数位置, 缺省为请求头
+	Description string           // 认证说明
+}
+
+// APIKeyHeader 通过请求头传递的API密钥认证
+//
+//	@param	name	string									请求头名称
+//	@param	verify	func(c *Context, key string) *Response	密钥校验方法, 为nil时仅要求密钥存在
+func APIKeyHeader(name string, verify func(c *Context, key string) *Response) *APIKey {
+	return &APIKey{Name: name, In: openapi.APIKeyInHeader, verify: verify}
+}
+
+// APIKeyQuery 通过查询参数传递的API密钥认证
+//
+//	@param	name	string									查询参数名称
+//	@param	verify	func(c *Context, key string) *Response	密钥校验方法, 为nil时仅要求密钥存在
+func APIKeyQuery(name string, verify func(c *Context, key string) *Response) *APIKey {
+	return &APIKey{Name: name, In: openapi.APIKeyInQuery, verify: verify}
+}
+
+// APIKeyCookie 通过cookie传递的API密钥认证
+//
+//	@param	name	string									cookie名称
+//	@param	verify	func(c *Context, key string) *Response	密钥校验方法, 为nil时仅要求密钥存在
+func APIKeyCookie(name string, verify func(c *Context, key string) *Response) *APIKey {
+	return &APIKey{Name: name, In: openapi.APIKeyInCookie, verify: verify}
+}
+
+// location 参数位置, 未设置时为请求头
+func (a *APIKey) location() openapi.APIKeyIn {
+	if a.In == "" {
+		return openapi.APIKeyInHeader
+	}
+	return a.In
+}
+
+func (a *APIKey) SchemeName() string {
+	in := string(a.location())
+	return "APIKey" + strings.ToUpper(in[:1]) + in[1:] + "_" + a.Name
+}
+
+func (a *APIKey) Scheme() *openapi.SecurityScheme {
+	return &openapi.SecurityScheme{
+		Type: openapi.SecurityTypeAPIKey, Name: a.Name, In: a.location(), Description: a.Description,
+	}
+}
+
+// Authenticate 校验API密钥, 校验通过后密钥保存于 Context.Credentials
+func (a *APIKey) Authenticate(c *Context) *Response {
+	var key string
+	switch a.location() {
+	case openapi.APIKeyInQuery:
+		key = c.ec.Query(a.Name)
+	case openapi.APIKeyInCookie:
+		key = c.ec.Cookies(a.Name)
+	default:
+		key = c.ec.Get(a.Name)
+	}
+	if key == "" {
+		return UnauthorizedResponse("", NotAuthenticated)
+	}
+	if a.verify != nil {
+		if resp := a.verify(c, key); resp != nil {
+			return resp
+		}
+	}
+
+	c.Credentials = key
+	return nil
+}
+
+// ------------------------------------- http basic ---------------------------------------
+
+// BasicAuth HTTP Basic 认证
+type BasicAuth struct {
+	verify      func(c *Context, credentials *HTTPBasicCredentials) *Response
+	Realm       string // 认证域, 用于 WWW-Authenticate 响应头
+	Description string // 认证说明
+}
+
+// HTTPBasic HTTP Basic 认证
+//
+//	@param	verify	func(c *Context, credentials *HTTPBasicCredentials) *Response	用户名密码校验方法, 为nil时仅要求凭证格式正确
+func HTTPBasic(verify func(c *Context, credentials *HTTPBasicCredentials) *Response) *BasicAuth {
+	return &BasicAuth{verify: verify}
+}
+
+func (a *BasicAuth) SchemeName() string { return "HTTPBasic" }
+
+func (a *BasicAuth) Scheme() *openapi.SecurityScheme {
+	return &openapi.SecurityScheme{Type: openapi.SecurityTypeHTTP, Scheme: "basic", Description: a.Description}
+}
+
+// Authenticate 校验用户名和密码, 校验通过后 *HTTPBasicCredentials 保存于 Context.Credentials
+func (a *BasicAuth) Authenticate(c *Context) *Response {
+	challenge := "Basic"
+	if a.Realm != "" {
+		challenge += ` realm="` + a.Realm + `"`
+	}
+
+	scheme, param := authorization(c)
+	if !strings.EqualFold(scheme, "basic") {
+		return UnauthorizedResponse(challenge, NotAuthenticated)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(param)
+	if err != nil {
+		return UnauthorizedResponse(challenge, "Invalid authentication credentials")
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return UnauthorizedResponse(challenge, "Invalid authentication credentials")
+	}
+
+	credentials := &HTTPBasicCredentials{Username: username, Password: password}
+	if a.verify != nil {
+		if resp := a.verify(c, credentials); resp != nil {
+			return resp
+		}
+	}
+
+	c.Credentials = credentials
+	return nil
+}
+
+// ------------------------------------- http bearer ---------------------------------------
+
+// BearerAuth HTTP Bearer 认证
+type BearerAuth struct {
+	verify       func(c *Context, credentials *HTTPAuthorizationCredentials) *Response
+	BearerFormat string // 令牌格式, 如: JWT
+	Description  string // 认证说明
+}
+
+// HTTPBearer HTTP Bearer 认证
+//
+//	@param	verify	func(c *Context, credentials *HTTPAuthorizationCredentials) *Response	令牌校验方法, 为nil时仅要求令牌存在
+func HTTPBearer(verify func(c *Context, credentials *HTTPAuthorizationCredentials) *Response) *BearerAuth {
+	return &BearerAuth{verify: verify}
+}
+
+func (a *BearerAuth) SchemeName() string { return "HTTPBearer" }
+
+func (a *BearerAuth) Scheme() *openapi.SecurityScheme {
+	return &openapi.SecurityScheme{
+		Type: openapi.SecurityTypeHTTP, Scheme: "bearer", BearerFormat: a.BearerFormat, Description: a.Description,
+	}
+}
+
+// Authenticate 校验令牌, 校验通过后 *HTTPAuthorizationCredentials 保存于 Context.Credentials
+func (a *BearerAuth) Authenticate(c *Context) *Response {
+	scheme, token := authorization(c)
+	if !strings.EqualFold(scheme, "bearer") || token == "" {
+		return UnauthorizedResponse("Bearer", NotAuthenticated)
+	}
+
+	credentials := &HTTPAuthorizationCredentials{Scheme: scheme, Credentials: token}
+	if a.verify != nil {
+		if resp := a.verify(c, credentials); resp != nil {
+			return resp
+		}
+	}
+
+	c.Credentials = credentials
+	return nil
+}
+
+// ------------------------------------- oauth2 password ---------------------------------------
+
+// OAuth2Password OAuth2 密码模式认证, 令牌通过 Authorization: Bearer 请求头传递
+type OAuth2Password struct {
+	verify      func(c *Context, token string) *Response
+	TokenUrl    string            // 获取令牌的路由
+	Scopes      map[string]string // 全部的授权范围及其说明
+	Description string            // 认证说明
+}
+
+// OAuth2PasswordBearer OAuth2 密码模式认证
+//
+//	@param	tokenUrl	string									获取令牌的路由, 其请求体通常为 OAuth2PasswordRequestForm
+//	@param	scopes		map[string]string						全部的授权范围及其说明
+//	@param	verify		func(c *Context, token string) *Response	令牌校验方法, 为nil时仅要求令牌存在
+func OAuth2PasswordBearer(tokenUrl string, scopes map[string]string, verify func(c *Context, token string) *Response) *OAuth2Password {
+	if scopes == nil {
+		scopes = map[string]string{}
+	}
+	return &OAuth2Password{TokenUrl: tokenUrl, Scopes: scopes, verify: verify}
+}
+
+func (a *OAuth2Password) SchemeName() string { return "OAuth2PasswordBearer" }
+
+func (a *OAuth2Password) Scheme() *openapi.SecurityScheme {
+	return &openapi.SecurityScheme{
+		Type:        openapi.SecurityTypeOAuth2,
+		Description: a.Description,
+		Flows: &openapi.OAuthFlows{
+			Password: &openapi.OAuthFlow{Scopes: a.Scopes, TokenUrl: a.TokenUrl},
+		},
+	}
+}
+
+// Authenticate 校验令牌, 校验通过后令牌字符串保存于 Context.Credentials
+func (a *OAuth2Password) Authenticate(c *Context) *Response {
+	scheme, token := authorization(c)
+	if !strings.EqualFold(scheme, "bearer") || token == "" {
+		return UnauthorizedResponse("Bearer", NotAuthenticated)
+	}
+	if a.verify != nil {
+		if resp := a.verify(c, token); resp != nil {
+			return resp
+		}
+	}
+
+	c.Credentials = token
+	return nil
+}
+
+// authorization 解析 Authorization 请求头, 返回认证方式和凭证
+func authorization(c *Context) (scheme, param string) {
+	scheme, param, _ = strings.Cut(strings.TrimSpace(c.ec.Get(fiber.HeaderAuthorization)), " ")
+	return scheme, strings.TrimSpace(param)
+}
+
+// UnauthorizedResponse 认证失败返回值, 状态码为401
+//
+//	@param	challenge	string	WWW-Authenticate 响应头, 为空时不设置
+//	@param	detail		string	错误信息
+//	@return	resp *Response response返回体
+func UnauthorizedResponse(challenge, detail string) *Response {
+	return AdvancedResponse(fiber.StatusUnauthorized, func(c *fiber.Ctx) error {
+		if challenge != "" {
+			c.Set(fiber.HeaderWWWAuthenticate, challenge)
+		}
+		return writeErrorDetail(c, fiber.StatusUnauthorized, detail)
+	})
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+func TestSecurityAuthenticate(t *testing.T) {
+	verifyKey := func(c *Context, key string) *Response {
+		if key != "secret" {
+			return UnauthorizedResponse("", "Invalid API key")
+		}
+		return nil
+	}
+	basic := func(username, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+
+	tests := []struct {
+		name        string
+		security    Security
+		scheme      map[string]any
+		setup       func(r *http.Request)
+		status      int
+		credentials string
+		challenge   string
+	}{
+		{
+			name: "api key header", security: APIKeyHeader("X-Token", verifyKey),
+			scheme: map[string]any{"type": "apiKey", "name": "X-Token", "in": "header"},
+			setup:  func(r *http.Request) { r.Header.Set("X-Token", "secret") }, status: http.StatusOK, credentials: "secret",
+		},
+		{
+			name: "api key header missing", security: APIKeyHeader("X-Token", verifyKey),
+			setup: func(r *http.Request) {}, status: http.StatusUnauthorized,
+		},
+		{
+			name: "api key header invalid", security: APIKeyHeader("X-Token", verifyKey),
+			setup: func(r *http.Request) { r.Header.Set("X-Token", "other") }, status: http.StatusUnauthorized,
+		},
+		{
+			name: "api key query", security: APIKeyQuery("token", verifyKey),
+			scheme: map[string]any{"type": "apiKey", "name": "token", "in": "query"},
+			setup:  func(r *http.Request) { r.URL.RawQuery = "token=secret" }, status: http.StatusOK, credentials: "secret",
+		},
+		{
+			name: "api key query in header", security: APIKeyQuery("token", verifyKey),
+			setup: func(r *http.Request) { r.Header.Set("token", "secret") }, status: http.StatusUnauthorized,
+		},
+		{
+			name: "api key cookie", security: APIKeyCookie("session", verifyKey),
+			scheme: map[string]any{"type": "apiKey", "name": "session", "in": "cookie"},
+			setup:  func(r *http.Request) { r.Header.Set("Cookie", "session=secret") }, status: http.StatusOK, credentials: "secret",
+		},
+		{
+			name: "api key cookie invalid", security: APIKeyCookie("session", verifyKey),
+			setup: func(r *http.Request) { r.Header.Set("Cookie", "session=other") }, status: http.StatusUnauthorized,
+		},
+		{
+			name: "basic", security: HTTPBasic(func(c *Context, credentials *HTTPBasicCredentials) *Response {
+				if credentials.Password != "pwd" {
+					return UnauthorizedResponse("Basic", "Incorrect username or password")
+				}
+				return nil
+			}),
+			scheme: map[string]any{"type": "http", "scheme": "basic"},
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", basic("lee", "pwd")) }, status: http.StatusOK, credentials: "lee",
+		},
+		{
+			name: "basic wrong password", security: HTTPBasic(func(c *Context, credentials *HTTPBasicCredentials) *Response {
+				if credentials.Password != "pwd" {
+					return UnauthorizedResponse("Basic", "Incorrect username or password")
+				}
+				return nil
+			}),
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", basic("lee", "other")) },
+			status: http.StatusUnauthorized, challenge: "Basic",
+		},
+		{
+			name: "basic malformed", security: &BasicAuth{Realm: "api"},
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Basic !!!") },
+			status: http.StatusUnauthorized, challenge: `Basic realm="api"`,
+		},
+		{
+			name: "basic missing", security: HTTPBasic(nil),
+			setup: func(r *http.Request) {}, status: http.StatusUnauthorized, challenge: "Basic",
+		},
+		{
+			name: "bearer", security: &BearerAuth{BearerFormat: "JWT"},
+			scheme: map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer abc") }, status: http.StatusOK, credentials: "abc",
+		},
+		{
+			name: "bearer rejected", security: HTTPBearer(func(c *Context, credentials *HTTPAuthorizationCredentials) *Response {
+				return UnauthorizedResponse("Bearer", "Invalid token")
+			}),
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", "Bearer abc") },
+			status: http.StatusUnauthorized, challenge: "Bearer",
+		},
+		{
+			name: "bearer basic scheme", security: HTTPBearer(nil),
+			setup:  func(r *http.Request) { r.Header.Set("Authorization", basic("lee", "pwd")) },
+			status: http.StatusUnauthorized, challenge: "Bearer",
+		},
+		{
+			name: "oauth2 password", security: OAuth2PasswordBearer("/token", map[string]string{"read": "读取"}, nil),
+			scheme: map[string]any{"type": "oauth2", "flows": map[string]any{
+				"password": map[string]any{"tokenUrl": "/token", "scopes": map[string]any{"read": "读取"}},
+			}},
+			setup: func(r *http.Request) { r.Header.Set("Authorization", "bearer abc") }, status: http.StatusOK, credentials: "abc",
+		},
+		{
+			name: "oauth2 password missing", security: OAuth2PasswordBearer("/token", nil, nil),
+			setup: func(r *http.Request) {}, status: http.StatusUnauthorized, challenge: "Bearer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewFlaskGo("test", "1.0.0", false, nil)
+			router := APIRouter("/api", nil)
+			router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+				switch v := c.Credentials.(type) {
+				case string:
+					return c.StringResponse(v)
+				case *HTTPBasicCredentials:
+					return c.StringResponse(v.Username)
+				case *HTTPAuthorizationCredentials:
+					return c.StringResponse(v.Credentials)
+				}
+				return c.StringResponse("")
+			}).AddSecurity(tt.security)
+			app.IncludeRouter(router)
+			client := NewTestClient(app)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/me", nil)
+			tt.setup(req)
+			resp := client.Do(req).AssertStatus(t, tt.status)
+			if tt.credentials != "" && resp.Text() != tt.credentials {
+				t.Errorf("expected credentials %q, got %q", tt.credentials, resp.Text())
+			}
+			if got := resp.Header.Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("expected WWW-Authenticate %q, got %q", tt.challenge, got)
+			}
+			if tt.scheme == nil {
+				return
+			}
+
+			bs, err := app.OpenAPI()
+			if err != nil {
+				t.Fatalf("generate openapi: %v", err)
+			}
+			doc := struct {
+				Components struct {
+					SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+				} `json:"components"`
+			}{}
+			if err = json.Unmarshal(bs, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Components.SecuritySchemes[tt.security.SchemeName()]; !reflect.DeepEqual(got, tt.scheme) {
+				t.Errorf("expected security scheme %s = %v, got %v", tt.security.SchemeName(), tt.scheme, got)
+			}
+		})
+	}
+}
@@ -1,34 +1,40 @@
 package app
 
 import (
-	"github.com/Chendemo12/flaskgo/internal/core"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
 	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/Chendemo12/functools/logger"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"mime/multipart"
 	"reflect"
 )
 
-const ( // json序列化错误, 关键信息的序号
-	jsoniterUnmarshalErrorSeparator = "|" // 序列化错误信息分割符, 定义于 validator/validator_instance.orSeparator
-	jsonErrorFieldMsgIndex          = 0   // 错误原因
-	jsonErrorFieldNameFormIndex     = 1   // 序列化错误的字段和值
-	jsonErrorFormIndex              = 3   // 接收到的数据
-)
-
 var emptyMap = map[string]any{}
 var emptyList = make([]string, 0)
 
 type Dict = map[string]any
 
 type Context struct {
-	PathFields  map[string]string `json:"path_fields,omitempty"`  // 路径参数
-	QueryFields map[string]string `json:"query_fields,omitempty"` // 查询参数
-	RequestBody any               `json:"request_body,omitempty"` // 请求体，初始值为1
-	app         *FlaskGo          `description:"flask-go application"`
-	ec          *fiber.Ctx        `description:"engine context"`
-	route       *Route            `description:"用于请求体和响应提校验"`
+	PathFields   map[string]string `json:"path_fields,omitempty"`   // 路径参数
+	QueryFields  map[string]string `json:"query_fields,omitempty"`  // 查询参数
+	PathValues   map[string]any    `json:"path_values,omitempty"`   // 路径参数, 已转换为定义的类型
+	QueryValues  map[string]any    `json:"query_values,omitempty"`  // 查询参数, 已转换为定义的类型
+	QueryParams  any               `json:"query_params,omitempty"`  // 自动绑定的查询参数结构体指针, 仅启用 EnableQueryBinding 时有效
+	HeaderFields map[string]any    `json:"header_fields,omitempty"` // 请求头参数, 已转换为定义的类型
+	CookieFields map[string]any    `json:"cookie_fields,omitempty"` // cookie参数, 已转换为定义的类型
+	RequestBody  any               `json:"request_body,omitempty"`  // 请求体，初始值为1
+	Credentials  any               `json:"credentials,omitempty"`   // 认证依赖项解析的凭证
+	Claims       JWTClaims         `json:"claims,omitempty"`        // JWT 认证解析的令牌载荷
+	app          *FlaskGo          `description:"flask-go application"`
+	ec           *fiber.Ctx        `description:"engine context"`
+	route        *Route            `description:"用于请求体和响应提校验"`
+
+	dependencies    map[*Dependency]any         `description:"依赖项返回值, 以依赖项为键"`
+	dependencyTypes map[reflect.Type]any        `description:"依赖项返回值, 以返回值类型为键"`
+	resolving       map[*Dependency]*Dependency `description:"正在执行的依赖项及其实际执行的依赖项, 用于检测循环依赖"`
+	cleanups        []func(outcome *Outcome)    `description:"依赖项清理函数"`
 }
 
 // Service 获取 FlaskGo 的 Service 服务依赖信息
@@ -55,11 +61,20 @@ func (c *Context) Logger() logger.Iface { return c.app.service.Logger() }
 // Deprecated: Console use Logger instead
 func (c *Context) Console() logger.Iface { return c.Logger() }
 
+// PathValue 获取转换为定义类型后的路径参数, 如: int64, float64, bool, time.Time; 参数不存在时为nil
+func (c *Context) PathValue(name string) any { return c.PathValues[name] }
+
+// QueryValue 获取转换为定义类型后的查询参数, 数组参数为切片; 参数不存在时为nil
+func (c *Context) QueryValue(name string) any { return c.QueryValues[name] }
+
 // Validator 获取请求体验证器
 func (c *Context) Validator() *validator.Validate { return c.app.service.validate }
 
 // Validate 结构体验证
-func (c *Context) Validate(stc any) *Response { return c.app.service.Validate(stc) }
+// 校验错误信息的语言由 Accept-Language 请求头决定
+func (c *Context) Validate(stc any) *Response {
+	return c.app.service.validateIn(stc, godantic.InBody, c.Language())
+}
 
 // BodyParser 序列化请求体
 //
@@ -68,7 +83,14 @@ func (c *Context) Validate(stc any) *Response { return c.app.service.Validate(st
 //	@return	*Response 错误信息,若为nil 则序列化成功
 func (c *Context) BodyParser(a any) *Response {
 	if err := c.Context().BodyParser(a); err != nil { // 请求的表单序列化错误
-		return ValidationErrorResponse(jsoniterUnmarshalErrorToValidationError(err))
+		if requestMediaType(c.ec) == fiber.MIMEApplicationJSON { // 定位json反序列化错误
+			return ValidationErrorResponse(c.validationError(
+				godantic.JsonDecodeError(c.ec.Body(), reflect.TypeOf(a), err, godantic.InBody),
+			))
+		}
+		return ValidationErrorResponse(&ValidationError{
+			Loc: []string{godantic.InBody}, Msg: err.Error(), Type: string(godantic.ObjectType), Ctx: emptyMap,
+		})
 	}
 
 	return nil
@@ -79,39 +101,125 @@ func (c *Context) ShouldBindJSON(stc any) *Response {
 	if err := c.BodyParser(stc); err != nil {
 		return err
 	}
-	if resp := c.app.service.Validate(stc); resp != nil {
+	if resp := c.Validate(stc); resp != nil {
+		return resp
+	}
+	return nil
+
+}
+
+// ShouldBindQuery 绑定查询参数到结构体并校验参数是否正确
+// 结构体的每一个导出字段均作为一个查询参数(以json标签为准)，缺省参数以 default 标签填充
+//
+//	@param	stc	any	结构体指针
+//	@return	*Response 错误信息,若为nil 则绑定成功
+func (c *Context) ShouldBindQuery(stc any) *Response {
+	if ve := godantic.BindQuery(stc, func(name string) []string { return queryValues(c.ec, name) }); ve != nil {
+		return ValidationErrorResponse(c.validationError(ve))
+	}
+	if resp := c.app.service.validateIn(stc, godantic.InQuery, c.Language()); resp != nil {
+		return resp
+	}
+	return nil
+}
+
+// ShouldBindForm 绑定 application/x-www-form-urlencoded 表单到结构体并校验表单是否正确
+// 结构体的每一个导出字段均作为一个表单字段(以json标签为准)，缺省字段以 default 标签填充
+//
+//	@param	stc	any	结构体指针
+//	@return	*Response 错误信息,若为nil 则绑定成功
+func (c *Context) ShouldBindForm(stc any) *Response {
+	if ve := godantic.BindForm(stc, func(name string) []string { return formValues(c.ec, name) }); ve != nil {
+		return ValidationErrorResponse(c.validationError(ve))
+	}
+	if resp := c.Validate(stc); resp != nil {
+		return resp
+	}
+	return nil
+}
+
+// ShouldBind 依据请求头 Content-Type 选择 ShouldBindForm, ShouldBindMultipart 或 ShouldBindJSON 绑定请求体
+//
+//	@param	stc	any	结构体指针
+//	@return	*Response 错误信息,若为nil 则绑定成功
+func (c *Context) ShouldBind(stc any) *Response {
+	switch requestMediaType(c.ec) {
+	case fiber.MIMEApplicationForm:
+		return c.ShouldBindForm(stc)
+	case fiber.MIMEMultipartForm:
+		return c.ShouldBindMultipart(stc)
+	default:
+		return c.ShouldBindJSON(stc)
+	}
+}
+
+// ShouldBindMultipart 绑定 multipart/form-data 表单到结构体并校验表单是否正确
+// 结构体通常嵌入了 godantic.FileModel，类型为 *multipart.FileHeader 或 []*multipart.FileHeader 的字段作为上传文件,
+// 其余字段作为表单字段(以json标签为准)
+//
+//	@param	stc	any	结构体指针
+//	@return	*Response 错误信息,若为nil 则绑定成功
+func (c *Context) ShouldBindMultipart(stc any) *Response {
+	form, err := c.ec.MultipartForm()
+	if err != nil {
+		return ValidationErrorResponse(&ValidationError{
+			Ctx:  map[string]any{"content_type": string(c.ec.Request().Header.ContentType())},
+			Msg:  err.Error(),
+			Type: string(godantic.ObjectType),
+			Loc:  []string{godantic.InBody},
+		})
+	}
+
+	if ve := godantic.BindMultipart(stc, form.Value, form.File); ve != nil {
+		return ValidationErrorResponse(c.validationError(ve))
+	}
+	if resp := c.Validate(stc); resp != nil {
 		return resp
 	}
 	return nil
+}
 
+// File 获取上传的文件, 若存在多个同名文件则返回第一个, 仅请求体为 multipart/form-data 时有效
+func (c *Context) File(name string) (*multipart.FileHeader, error) { return c.ec.FormFile(name) }
+
+// Files 获取上传的全部同名文件, 仅请求体为 multipart/form-data 时有效
+func (c *Context) Files(name string) []*multipart.FileHeader {
+	form, err := c.ec.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	return form.File[name]
 }
 
-func (c *Context) structResponseValidation(content any) *ValidationError {
+// FormValue 获取表单字段的值
+func (c *Context) FormValue(name string) string { return c.ec.FormValue(name) }
+
+// structResponseValidation 依据路由中与状态码相匹配的响应模型校验返回值的类型和字段
+// 校验 required、oneof 和 gte/lte 标签，对于 List 类型的返回值会逐个校验数组元素
+func (c *Context) structResponseValidation(statusCode int, content any) []*ValidationError {
+	if c.app.flags.ResponseValidateDisabled || c.route == nil {
+		return nil
+	}
+	model := c.route.ResponseModelFor(statusCode)
 	// 对于 struct 类型，允许缺省返回值以屏蔽返回值校验
-	if core.ResponseValidateDisabled || c.route.ResponseModel == nil {
+	if model == nil {
 		return nil
 	}
 
-	rt := reflect.TypeOf(content)
-	if rt.Kind() == reflect.Ptr {
-		rt = rt.Elem()
+	errs := godantic.ValidateModel(model, content, "response")
+	if len(errs) == 0 {
+		return nil
 	}
 
-	// 类型校验
-	meta, err := c.route.ResponseModel.Metadata()
-	if err != nil || meta.String() != rt.String() {
-		v := &ValidationError{
-			Ctx:  emptyMap,
-			Msg:  ModelNotMatch,
-			Type: string(godantic.StringType),
-			Loc:  []string{rt.String()},
-		}
-		c.Logger().Error(v.SchemaDesc(), ":", v.String())
-		return v
+	ves := make([]*ValidationError, len(errs))
+	for i := 0; i < len(errs); i++ {
+		ves[i] = c.validationError(errs[i])
+		c.Logger().Error(
+			"response validation failed: ", c.ec.Method(), " ", c.ec.Route().Path, ", ", ves[i].String(),
+		)
 	}
-	// 字段类型校验
 
-	return nil
+	return ves
 }
 
 // OKResponse 返回状态码为200的 JSONResponse
@@ -119,9 +227,6 @@ func (c *Context) structResponseValidation(content any) *ValidationError {
 //	@param	content	any	可以json序列化的类型
 //	@return	resp *Response response返回体
 func (c *Context) OKResponse(content any) *Response {
-	if err := c.structResponseValidation(content); err != nil {
-		return ValidationErrorResponse(err)
-	}
 	return OKResponse(content)
 }
 
@@ -131,9 +236,6 @@ func (c *Context) OKResponse(content any) *Response {
 //	@param	content		any	可以json序列化的类型
 //	@return	resp *Response response返回体
 func (c *Context) JSONResponse(statusCode int, content any) *Response {
-	if err := c.structResponseValidation(content); err != nil {
-		return ValidationErrorResponse(err)
-	}
 	return JSONResponse(statusCode, content)
 }
 
@@ -189,6 +291,14 @@ func (c *Context) ErrorResponse(content any) *Response {
 	return ErrorResponse(content)
 }
 
+// ExceptionResponse 以错误作为返回值, 此错误会交由异常处理函数处理
+//
+//	@param	err	error	错误, 如: *HTTPException
+//	@return	resp *Response response返回体
+func (c *Context) ExceptionResponse(err error) *Response {
+	return ExceptionResponse(err)
+}
+
 // HTMLResponse 返回一段HTML文本
 //
 //	@param	statusCode	int		响应状态码
@@ -228,11 +338,13 @@ type CustomService interface {
 // 此对象由FlaskGo启动时自动创建，此对象不应被修改，组合和嵌入，
 // 但可通过SetServiceContext()接口设置自定义的上下文信息，并在每一个路由钩子函数中可得
 type Service struct {
-	logger   logger.Iface        `description:"日志对象"`
-	ctx      CustomService       `description:"上层自定义服务依赖"`
-	validate *validator.Validate `description:"请求体验证包"`
-	openApi  *openapi.OpenApi    `description:"模型文档"`
-	addr     string              `description:"绑定地址"`
+	logger     logger.Iface            `description:"日志对象"`
+	ctx        CustomService           `description:"上层自定义服务依赖"`
+	validate   *validator.Validate     `description:"请求体验证包"`
+	translator *ut.UniversalTranslator `description:"校验错误信息翻译器"`
+	openApi    *openapi.OpenApi        `description:"模型文档"`
+	addr       string                  `description:"绑定地址"`
+	language   string                  `description:"校验错误信息的默认语言"`
 }
 
 // Config 获取自定义配置文件
@@ -271,8 +383,21 @@ func (s *Service) ReplaceLogger(logger logger.Iface) { s.logger = logger }
 // Validator 获取请求体验证器
 func (s *Service) Validator() *validator.Validate { return s.validate }
 
-// Validate 结构体验证
-func (s *Service) Validate(stc any) *Response {
+// Validate 结构体验证, 校验错误信息为默认语言
+func (s *Service) Validate(stc any) *Response { return s.validateIn(stc, godantic.InBody, s.language) }
+
+// Language 校验错误信息的默认语言
+func (s *Service) Language() string { return s.language }
+
+// Translator 获取校验错误信息翻译器, 可通过其为自定义校验标签注册翻译
+func (s *Service) Translator() *ut.UniversalTranslator { return s.translator }
+
+// validateIn 结构体验证, 并以 loc 作为错误定位的起点
+//
+//	@param	stc		any		结构体
+//	@param	loc		string	参数位置, 如 "body" 或 "query"
+//	@param	lang	string	校验错误信息的语言, 如 "zh" 或 "en"
+func (s *Service) validateIn(stc any, loc string, lang string) *Response {
 	err := s.validate.Struct(stc)
 	if err != nil { // 模型验证错误
 		err, _ := err.(validator.ValidationErrors) // validator的校验错误信息
@@ -283,8 +408,8 @@ func (s *Service) Validate(stc any) *Response {
 			ves := make([]*ValidationError, nums) // 自定义的错误信息
 			for i := 0; i < nums; i++ {
 				ves[i] = &ValidationError{
-					Loc:  []string{"body", err[i].Field()},
-					Msg:  err[i].Error(),
+					Loc:  []string{loc, err[i].Field()},
+					Msg:  s.translateError(reflect.TypeOf(stc), err[i], lang),
 					Type: err[i].Type().String(),
 					Ctx:  emptyMap,
 				}
@@ -2,12 +2,18 @@ package app
 
 import (
 	"bytes"
-	"github.com/Chendemo12/flaskgo/internal/core"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
 	"github.com/Chendemo12/flaskgo/internal/openapi"
 	"github.com/Chendemo12/functools/python"
 	"github.com/gofiber/fiber/v2"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 type DebugMode struct {
@@ -19,70 +25,246 @@ func (d DebugMode) SchemaDesc() string { return "调试模式模型" }
 
 func (f *FlaskGo) createOpenApiDoc() {
 	// 不允许创建swag文档
-	if python.All(!core.IsDebug(), core.SwaggerDisabled) {
+	if python.All(!f.IsDebug(), f.flags.SwaggerDisabled) {
 		return
 	}
 
-	f.service.openApi = openapi.NewOpenApi(f.title, f.version, f.Description())
-
-	f.createDefines()
-	f.createPaths()
+	f.service.openApi = f.makeOpenApi(f.APIRouters(), f.docs.Version)
 	f.createSwaggerRoutes()
+
+	// 调试模式下检查文档是否符合规范
+	if f.IsDebug() {
+		if err := f.service.openApi.Validate(); err != nil {
+			f.service.Logger().Warn(err.Error())
+		}
+	}
+}
+
+// makeOpenApi 依据路由组生成 openapi 文档
+//
+//	@param	routers	[]*Router	路由组
+//	@param	docVersion	string	文档版本, openapi.Version303 或 openapi.Version310
+//	@return	*openapi.OpenApi 文档
+func (f *FlaskGo) makeOpenApi(routers []*Router, docVersion string) *openapi.OpenApi {
+	version := f.version
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	api := openapi.NewOpenApi(f.title, version, f.Description())
+	api.SetVersion(docVersion)
+	if f.flags.ProblemDetailsEnabled {
+		api.UseProblemDetails()
+	}
+
+	createDefines(routers, api)
+	createPaths(routers, api)
+
+	return api
+}
+
+// OpenAPI 生成 json 格式的 openapi 文档, 无需启动服务, 通常用于在 CI 中导出文档并比较不同版本间的差异;
+// 若应用已初始化则返回其文档, 否则依据已注册的路由组(及按需挂载的基础路由)生成, 且不会修改应用的状态,
+// 因此可在 Run 之前调用; 文档不受 DisableSwagAutoCreate 影响
+//
+//	@return	[]byte 文档
+//	@return	error 序列化错误, 或文档不符合 OpenApi 规范时的 *openapi.SpecError, 后者仍会返回文档
+func (f *FlaskGo) OpenAPI() ([]byte, error) {
+	return f.openAPI(f.docs.Version)
+}
+
+// OpenAPIYAML 生成 yaml 格式的 openapi 文档, 同 OpenAPI
+func (f *FlaskGo) OpenAPIYAML() ([]byte, error) {
+	return f.openAPIYAML(f.docs.Version)
+}
+
+// WriteOpenAPI 将 openapi 文档写入文件, 扩展名为 .yaml 或 .yml 时写入 yaml 格式, 否则写入 json 格式;
+// 文档不符合 OpenApi 规范时仍会写入文件, 并返回 *openapi.SpecError
+//
+//	@param	filename	string	文件路径, 如: docs/openapi.yaml
+func (f *FlaskGo) WriteOpenAPI(filename string) error {
+	return f.writeOpenAPI(filename, f.docs.Version)
+}
+
+func (f *FlaskGo) openAPI(docVersion string) ([]byte, error) {
+	bs, err := f.openApiDoc(docVersion).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return bs, openapi.Validate(bs)
+}
+
+func (f *FlaskGo) openAPIYAML(docVersion string) ([]byte, error) {
+	bs, err := f.openAPI(docVersion)
+	if bs == nil {
+		return nil, err
+	}
+	ys, yerr := openapi.JsonToYaml(bs)
+	if yerr != nil {
+		return nil, yerr
+	}
+	return ys, err
+}
+
+func (f *FlaskGo) writeOpenAPI(filename, docVersion string) error {
+	var bs []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		bs, err = f.openAPIYAML(docVersion)
+	default:
+		bs, err = f.openAPI(docVersion)
+	}
+	if bs == nil {
+		return err
+	}
+
+	if werr := os.WriteFile(filename, bs, 0644); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// OpenAPIMain 导出文档的命令行程序入口, 无需启动服务, 通常用于在 CI 中提交文档并比较不同版本间的差异;
+// 解析命令行参数 -o(输出文件, 缺省为 openapi.json) 和 -openapi(文档版本, 缺省为应用的设置) 后写入文件,
+// 参数错误时以状态码 2 退出, 写入失败或文档不符合 OpenApi 规范时以状态码 1 退出(文档仍会写入).
+// 在项目中创建独立的 main 包, 注册路由组后调用:
+//
+//	func main() {
+//		app := flaskgo.NewFlaskGo("example", "1.0.0", false, nil)
+//		app.IncludeRouter(router.UserRouter())
+//		flaskgo.OpenAPIMain(app)
+//	}
+//
+//	go run ./cmd/openapi -o docs/openapi.yaml -openapi 3.1.0
+//
+//	@param	app	*FlaskGo	已注册路由组的应用
+func OpenAPIMain(app *FlaskGo) {
+	os.Exit(app.openAPIMain(os.Args[1:], os.Stderr))
+}
+
+// openAPIMain 解析命令行参数并导出文档, 返回程序的退出状态码
+func (f *FlaskGo) openAPIMain(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", "openapi.json", "输出文件, 扩展名为 .yaml 或 .yml 时输出 yaml 格式")
+	version := fs.String("openapi", f.docs.Version, "文档版本, 3.0.3 或 3.1.0")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *version != openapi.Version303 && *version != openapi.Version310 {
+		fmt.Fprintf(stderr, "unsupported openapi version: %s\n", *version)
+		return 2
+	}
+
+	// 按参数指定的版本生成文档, 不修改应用的文档设置
+	if err := f.writeOpenAPI(*output, *version); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// openApiDoc 获取指定版本的应用文档, 应用尚未初始化(或未创建文档)或其文档版本不同时依据已注册的路由组生成
+func (f *FlaskGo) openApiDoc(docVersion string) *openapi.OpenApi {
+	if f.service.openApi != nil && f.service.openApi.Version == docVersion {
+		return f.service.openApi
+	}
+
+	routers := f.APIRouters()
+	if f.engine == nil && f.baseRoutesEnabled() { // 尚未挂载基础路由
+		routers = append(routers[:len(routers):len(routers)], f.baseRouter())
+	}
+	return f.makeOpenApi(routers, docVersion)
 }
 
 // 注册 swagger 的文档路由
 func (f *FlaskGo) createSwaggerRoutes() {
+	docs := f.docs
+
 	// docs 在线调试页面
-	f.engine.Get("/docs", func(c *fiber.Ctx) error {
+	f.engine.Get(docs.DocsUrl, func(c *fiber.Ctx) error {
 		c.Set(fiber.HeaderContentType, fiber.MIMETextHTML)
 		return c.SendString(openapi.MakeSwaggerUiHtml(
 			f.title,
-			openapi.JsonUrl,
-			openapi.SwaggerJsUrl,
-			openapi.SwaggerCssUrl,
-			openapi.SwaggerFaviconUrl,
+			docs.OpenapiUrl,
+			docs.SwaggerJsUrl,
+			docs.SwaggerCssUrl,
+			docs.SwaggerFaviconUrl,
+			docs.Oauth2RedirectUrl(),
 		))
 	})
 
+	// swagger OAuth2 认证回调页面
+	f.engine.Get(docs.Oauth2RedirectUrl(), func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTML)
+		return c.SendString(openapi.MakeOauth2RedirectHtml())
+	})
+
 	// redoc 纯文档页面
-	f.engine.Get("/redoc", func(c *fiber.Ctx) error {
+	f.engine.Get(docs.RedocUrl, func(c *fiber.Ctx) error {
 		c.Set(fiber.HeaderContentType, fiber.MIMETextHTML)
 		return c.SendString(openapi.MakeRedocUiHtml(
 			f.title,
-			openapi.JsonUrl,
-			openapi.RedocJsUrl,
-			openapi.RedocFaviconUrl,
+			docs.OpenapiUrl,
+			docs.RedocJsUrl,
+			docs.RedocFaviconUrl,
+			docs.RedocFontsUrl,
 		))
 	})
 
 	// openapi 获取路由定义
-	f.engine.Get("/openapi.json", func(c *fiber.Ctx) error {
+	f.engine.Get(docs.OpenapiUrl, func(c *fiber.Ctx) error {
 		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
 		//return c.Status(fiber.StatusOK).SendString(templateString)
 		return c.Status(fiber.StatusOK).SendStream(bytes.NewReader(f.service.openApi.Schema()))
 	})
+
+	// 文档页面的本地静态文件
+	if docs.StaticUrl != "" {
+		f.engine.Get(docs.StaticUrl+"/:name", func(c *fiber.Ctx) error {
+			bs, err := openapi.StaticFile(c.Params("name"))
+			if err != nil {
+				return fiber.ErrNotFound
+			}
+			c.Type(filepath.Ext(c.Params("name")))
+			c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+			return c.Send(bs)
+		})
+	}
 }
 
 // 生成模型定义
-func (f *FlaskGo) createDefines() {
-	for _, router := range f.APIRouters() {
+func createDefines(routers []*Router, api *openapi.OpenApi) {
+	for _, router := range routers {
 		for _, route := range router.Routes() {
 			if route.RequestModel != nil {
 				// 内部会处理嵌入类型
-				f.service.openApi.AddDefinition(route.RequestModel)
+				api.AddDefinition(route.RequestModel)
 			}
 			if route.ResponseModel != nil {
-				f.service.openApi.AddDefinition(route.ResponseModel)
+				api.AddDefinition(route.ResponseModel)
+			}
+			for _, resp := range route.Responses {
+				if resp.Model != nil {
+					api.AddDefinition(resp.Model)
+				}
+			}
+			for _, s := range route.Securities {
+				api.AddSecurityScheme(s.SchemeName(), s.Scheme())
 			}
 		}
 	}
 }
 
 // 生成路由定义
-func (f *FlaskGo) createPaths() {
-	for _, router := range f.APIRouters() {
+func createPaths(routers []*Router, api *openapi.OpenApi) {
+	for _, router := range routers {
 		for _, route := range router.Routes() {
-			routeToPathItem(router, route, f.service.openApi)
+			routeToPathItem(router, route, api)
 		}
 	}
 }
@@ -122,16 +304,40 @@ func routeToPathItem(router *Router, route *Route, api *openapi.OpenApi) {
 		queryParams[no] = p
 	}
 
+	// 构造请求头参数和cookie参数
+	for _, q := range append(route.HeaderFields, route.CookieFields...) {
+		p := openapi.QModelToParameter(q)
+		p.Deprecated = route.deprecated
+		queryParams = append(queryParams, p)
+	}
+
 	// 构造操作符
 	operation := &openapi.Operation{
 		Summary:     route.Summary,
 		Description: route.Description,
 		Tags:        route.Tags,
 		Parameters:  append(pathParams, queryParams...),
-		RequestBody: openapi.MakeOperationRequestBody(route.RequestModel),
-		Responses:   openapi.MakeOperationResponses(route.ResponseModel),
+		RequestBody: openapi.MakeOperationRequestBody(route.RequestModel, route.mimeTypes()...),
+		Responses:   routeResponses(route, api.Components.ProblemDetails),
 		Deprecated:  route.deprecated,
 	}
+	if len(route.Securities) > 0 { // 全部认证依赖项均需满足
+		requirement := openapi.SecurityRequirement{}
+		for _, s := range route.Securities {
+			// 3.0 中仅 oauth2 和 openIdConnect 可以声明权限范围, 其他认证方式为空数组; 3.1 允许其他认证方式声明角色名称
+			switch s.Scheme().Type {
+			case openapi.SecurityTypeOAuth2, openapi.SecurityTypeOpenIdConnect:
+				requirement[s.SchemeName()] = append([]string{}, route.Scopes...)
+			default:
+				if api.Is31() {
+					requirement[s.SchemeName()] = append([]string{}, route.Scopes...)
+				} else {
+					requirement[s.SchemeName()] = []string{}
+				}
+			}
+		}
+		operation.Security = []openapi.SecurityRequirement{requirement}
+	}
 
 	// 绑定到操作方法
 	switch route.Method {
@@ -153,3 +359,34 @@ func routeToPathItem(router *Router, route *Route, api *openapi.OpenApi) {
 		item.Get = operation
 	}
 }
+
+// routeResponses 构造路由的响应文档, 附加响应会覆盖默认的 200 和 422 响应
+//
+//	@param	route	*Route	路由
+//	@param	problem	bool	422 响应是否采用 RFC 7807 格式
+func routeResponses(route *Route, problem bool) []*openapi.Response {
+	responses := openapi.MakeOperationResponses(route.ResponseModel)
+	if problem {
+		for i := 0; i < len(responses); i++ {
+			if responses[i] == openapi.Resp422 {
+				responses[i] = openapi.Resp422Problem
+			}
+		}
+	}
+	for _, resp := range route.Responses {
+		r := openapi.MakeOperationResponse(resp.StatusCode, resp.Model, resp.Description)
+		replaced := false
+		for i := 0; i < len(responses); i++ {
+			if responses[i].StatusCode == resp.StatusCode {
+				responses[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			responses = append(responses, r)
+		}
+	}
+
+	return responses
+}
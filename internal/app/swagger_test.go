@@ -0,0 +1,169 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/flaskgo/internal/openapi"
+)
+
+func TestOpenAPIMain(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		file    string
+		version string
+	}{
+		{name: "default json", args: nil, code: 0, file: "openapi.json", version: `"openapi":"3.0.3"`},
+		{name: "yaml 3.1", args: []string{"-o", "openapi.yaml", "-openapi", "3.1.0"}, code: 0, file: "openapi.yaml", version: "openapi: 3.1.0"},
+		{name: "unsupported version", args: []string{"-openapi", "2.0"}, code: 2},
+		{name: "unknown flag", args: []string{"-x"}, code: 2},
+		{name: "help", args: []string{"-h"}, code: 0},
+		{name: "write error", args: []string{"-o", filepath.Join("missing", "openapi.json")}, code: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wd, _ := os.Getwd()
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(wd)
+
+			stderr := &bytes.Buffer{}
+			if code := newTestUserApp().openAPIMain(tt.args, stderr); code != tt.code {
+				t.Fatalf("expected exit code %d, got %d: %s", tt.code, code, stderr)
+			}
+			if tt.file == "" {
+				return
+			}
+
+			bs, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			if !strings.Contains(string(bs), tt.version) || !strings.Contains(string(bs), "/api/user/{id}") {
+				t.Errorf("expected %s document with the registered routes, got %s", tt.version, bs)
+			}
+			if tt.version == `"openapi":"3.0.3"` {
+				if err = openapi.Validate(bs); err != nil {
+					t.Errorf("expected a valid document, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPIMainInitialized(t *testing.T) {
+	app := newTestUserApp()
+	client := NewTestClient(app) // 初始化应用并创建 3.0.3 版本的文档
+	output := filepath.Join(t.TempDir(), "openapi.json")
+
+	stderr := &bytes.Buffer{}
+	if code := app.openAPIMain([]string{"-o", output, "-openapi", openapi.Version310}, stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	bs, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if !strings.Contains(string(bs), `"openapi":"3.1.0"`) || !strings.Contains(string(bs), "/api/user/{id}") {
+		t.Errorf("expected a 3.1.0 document with the registered routes, got %s", bs)
+	}
+
+	// 应用自身的文档设置不受影响
+	if app.docs.Version != openapi.Version303 {
+		t.Errorf("expected the app to keep version %s, got %s", openapi.Version303, app.docs.Version)
+	}
+	resp := client.Get(app.docs.OpenapiUrl, nil).AssertStatus(t, http.StatusOK)
+	if !strings.Contains(resp.Text(), `"openapi":"3.0.3"`) {
+		t.Errorf("expected the served document to stay 3.0.3, got %s", resp.Text())
+	}
+}
+
+func TestOpenAPISecurityScopes(t *testing.T) {
+	jwtBearer := func(tokenUrl string) Security {
+		auth := JWTBearer(&JWTKey{Alg: JWTAlgHS256, Key: []byte("secret")})
+		auth.TokenUrl = tokenUrl
+		return auth
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		security Security
+		scopes   []any
+	}{
+		{name: "jwt", version: openapi.Version303, security: jwtBearer(""), scopes: []any{}},
+		{name: "jwt oauth2", version: openapi.Version303, security: jwtBearer("/token"), scopes: []any{"read"}},
+		{name: "api key", version: openapi.Version303, security: APIKeyHeader("X-Token", nil), scopes: []any{}},
+		{name: "jwt", version: openapi.Version310, security: jwtBearer(""), scopes: []any{"read"}},
+		{name: "api key", version: openapi.Version310, security: APIKeyHeader("X-Token", nil), scopes: []any{"read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+"/"+tt.name, func(t *testing.T) {
+			app := NewFlaskGo("test", "1.0.0", false, nil).SetOpenApiVersion(tt.version)
+			router := APIRouter("/api", nil)
+			router.GET("/me", godantic.String, "当前用户", func(c *Context) *Response {
+				return c.StringResponse("ok")
+			}).AddSecurity(tt.security).SetScopes("read")
+			app.IncludeRouter(router)
+
+			bs, err := app.OpenAPI()
+			if err != nil {
+				t.Fatalf("generate openapi: %v", err)
+			}
+			doc := map[string]any{}
+			if err = json.Unmarshal(bs, &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			op := doc["paths"].(map[string]any)["/api/me"].(map[string]any)["get"].(map[string]any)
+			security, _ := op["security"].([]any)
+			if len(security) != 1 {
+				t.Fatalf("expected one security requirement, got %v", op["security"])
+			}
+			if got := security[0].(map[string]any)[tt.security.SchemeName()]; !reflect.DeepEqual(got, tt.scopes) {
+				t.Errorf("expected %s scopes %v, got %v", tt.security.SchemeName(), tt.scopes, got)
+			}
+		})
+	}
+}
+
+func TestRedocFonts(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(app *FlaskGo)
+		fonts string
+	}{
+		{name: "local assets", setup: func(app *FlaskGo) {}},
+		{name: "cdn assets", setup: func(app *FlaskGo) { app.UseCDNDocsAssets() }, fonts: openapi.RedocFontsUrl},
+		{name: "custom", setup: func(app *FlaskGo) { app.SetRedocFontsUrl("/static/fonts.css") }, fonts: "/static/fonts.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestUserApp()
+			tt.setup(app)
+			page := NewTestClient(app).Get(openapi.RedocUrl, nil).AssertStatus(t, http.StatusOK).Text()
+
+			if tt.fonts == "" && strings.Contains(page, "rel=\"stylesheet\"") {
+				t.Errorf("expected no external fonts, got %s", page)
+			}
+			if tt.fonts != "" && !strings.Contains(page, `<link href="`+tt.fonts+`" rel="stylesheet">`) {
+				t.Errorf("expected fonts %s, got %s", tt.fonts, page)
+			}
+		})
+	}
+}
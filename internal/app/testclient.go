@@ -0,0 +1,216 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/Chendemo12/functools/helper"
+	"github.com/gofiber/fiber/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// TestingT 测试断言所需的 testing.TB 方法, 避免在非测试代码中引入 testing 包
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// TestClient 进程内测试客户端, 通过 fiber.App.Test 直接执行请求, 而无需监听端口
+// 创建时会完成应用的初始化(挂载路由和生成文档), 但不会执行启动事件、定时任务和注册信号处理
+//
+//	client := flaskgo.NewTestClient(app)
+//	resp := client.PostJSON("/api/user", &User{Name: "lee"}).AssertStatus(t, http.StatusOK)
+//	user := &User{}
+//	_ = resp.Decode(user)
+type TestClient struct {
+	app     *FlaskGo
+	Headers http.Header // 每一个请求都会携带的请求头, 如: Authorization
+}
+
+// NewTestClient 创建一个测试客户端, 若应用尚未初始化则首先初始化应用
+//
+//	@param	app	*FlaskGo	应用
+//	@return	*TestClient 测试客户端
+func NewTestClient(app *FlaskGo) *TestClient {
+	if app.engine == nil {
+		app.isFieldsOk().initialize()
+	}
+	return &TestClient{app: app, Headers: http.Header{}}
+}
+
+// App 被测试的应用
+func (t *TestClient) App() *FlaskGo { return t.app }
+
+// SetHeader 设置每一个请求都会携带的请求头
+func (t *TestClient) SetHeader(key, value string) *TestClient {
+	t.Headers.Set(key, value)
+	return t
+}
+
+// Do 执行一个请求, 请求不会超时
+//
+//	@param	req	*http.Request	请求
+//	@return	*TestResponse 响应, 若请求失败则 TestResponse.Err 不为nil
+func (t *TestClient) Do(req *http.Request) *TestResponse {
+	for key, values := range t.Headers {
+		if req.Header.Get(key) == "" {
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+	}
+
+	resp, err := t.app.engine.Test(req, -1)
+	if err != nil {
+		return &TestResponse{Err: err, Header: http.Header{}}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return &TestResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, Err: err}
+}
+
+// Request 执行一个请求
+//
+//	@param	method		string		请求方法
+//	@param	path		string		请求路由
+//	@param	body		io.Reader	请求体, 允许为nil
+//	@param	contentType	string		请求体格式, 为空时不设置
+//	@return	*TestResponse 响应
+func (t *TestClient) Request(method, path string, body io.Reader, contentType string) *TestResponse {
+	req := httptest.NewRequest(method, path, body)
+	if contentType != "" {
+		req.Header.Set(fiber.HeaderContentType, contentType)
+	}
+	return t.Do(req)
+}
+
+// Get 执行 GET 请求
+//
+//	@param	path	string		请求路由
+//	@param	query	url.Values	查询参数, 允许为nil
+func (t *TestClient) Get(path string, query url.Values) *TestResponse {
+	return t.Request(http.MethodGet, withQuery(path, query), nil, "")
+}
+
+// Delete 执行 DELETE 请求
+//
+//	@param	path	string		请求路由
+//	@param	query	url.Values	查询参数, 允许为nil
+func (t *TestClient) Delete(path string, query url.Values) *TestResponse {
+	return t.Request(http.MethodDelete, withQuery(path, query), nil, "")
+}
+
+// PostJSON 以 application/json 格式执行 POST 请求
+//
+//	@param	path	string	请求路由
+//	@param	body	any		请求体, 会被序列化为json
+func (t *TestClient) PostJSON(path string, body any) *TestResponse {
+	return t.sendJSON(http.MethodPost, path, body)
+}
+
+// PutJSON 以 application/json 格式执行 PUT 请求
+func (t *TestClient) PutJSON(path string, body any) *TestResponse {
+	return t.sendJSON(http.MethodPut, path, body)
+}
+
+// PatchJSON 以 application/json 格式执行 PATCH 请求
+func (t *TestClient) PatchJSON(path string, body any) *TestResponse {
+	return t.sendJSON(http.MethodPatch, path, body)
+}
+
+// PostForm 以 application/x-www-form-urlencoded 格式执行 POST 请求
+//
+//	@param	path	string		请求路由
+//	@param	form	url.Values	表单
+func (t *TestClient) PostForm(path string, form url.Values) *TestResponse {
+	return t.Request(http.MethodPost, path, strings.NewReader(form.Encode()), fiber.MIMEApplicationForm)
+}
+
+// OpenAPI 获取应用生成的 openapi 文档, 缺省路由为 /openapi.json
+//
+//	@return	*TestResponse 响应, 其响应体为文档
+func (t *TestClient) OpenAPI() *TestResponse {
+	return t.Get(t.app.docs.OpenapiUrl, nil)
+}
+
+func (t *TestClient) sendJSON(method, path string, body any) *TestResponse {
+	bs, err := helper.DefaultJsonMarshal(body)
+	if err != nil {
+		return &TestResponse{Err: err, Header: http.Header{}}
+	}
+	return t.Request(method, path, bytes.NewReader(bs), fiber.MIMEApplicationJSON)
+}
+
+// withQuery 拼接查询参数
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + query.Encode()
+	}
+	return path + "?" + query.Encode()
+}
+
+// TestResponse 测试客户端的响应
+type TestResponse struct {
+	Header     http.Header // 响应头
+	Err        error       // 请求错误
+	Body       []byte      // 响应体
+	StatusCode int         // 响应状态码
+}
+
+// Text 响应体字符串
+func (r *TestResponse) Text() string { return string(r.Body) }
+
+// AssertStatus 断言响应状态码, 请求失败或状态码不一致时报告测试失败
+//
+//	@param	t		TestingT	*testing.T
+//	@param	code	int			期望的状态码
+//	@return	*TestResponse 响应本身, 便于链式调用
+func (r *TestResponse) AssertStatus(t TestingT, code int) *TestResponse {
+	t.Helper()
+	if r.Err != nil {
+		t.Errorf("request failed: %v", r.Err)
+	} else if r.StatusCode != code {
+		t.Errorf("expected status code %d, got %d: %s", code, r.StatusCode, r.Body)
+	}
+	return r
+}
+
+// JSON 将响应体反序列化到 v
+func (r *TestResponse) JSON(v any) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	return helper.DefaultJsonUnmarshal(r.Body, v)
+}
+
+// Decode 将响应体反序列化到 v, 若 v 为 BaseModel 等模型则同时依据模型定义进行校验
+//
+//	@param	v	any	结构体指针
+//	@return	error 反序列化或校验错误
+func (r *TestResponse) Decode(v any) error {
+	if err := r.JSON(v); err != nil {
+		return err
+	}
+
+	model, ok := v.(godantic.SchemaIface)
+	if !ok {
+		return nil
+	}
+	registerModel(model) // 反序列化得到的新实例尚未关联模型的元信息
+	if ves := godantic.ValidateModel(model, v); len(ves) > 0 {
+		msgs := make([]string, len(ves))
+		for i, ve := range ves {
+			msgs[i] = strings.Join(ve.Loc, ".") + ": " + ve.Msg
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+type testUser struct {
+	godantic.BaseModel
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age" validate:"gte=0,lte=150"`
+}
+
+type testUserQuery struct {
+	godantic.QueryModel
+	Name string `json:"name" validate:"required"`
+}
+
+func newTestUserApp() *FlaskGo {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", []string{"User"})
+	router.POST("/user", &testUser{}, &testUser{}, "创建用户", func(c *Context) *Response {
+		user := &testUser{}
+		if resp := c.ShouldBindJSON(user); resp != nil {
+			return resp
+		}
+		user.Age++
+		return c.OKResponse(user)
+	})
+	router.GET("/user/:id", &testUser{}, "获取用户", func(c *Context) *Response {
+		return c.OKResponse(&testUser{Name: c.QueryFields["name"] + "-" + c.PathFields["id"], Age: 18})
+	}, &testUserQuery{})
+	app.IncludeRouter(router)
+
+	return app
+}
+
+func TestTestClient(t *testing.T) {
+	client := NewTestClient(newTestUserApp())
+
+	tests := []struct {
+		name   string
+		do     func() *TestResponse
+		status int
+		want   *testUser
+	}{
+		{
+			name:   "post json",
+			do:     func() *TestResponse { return client.PostJSON("/api/user", &testUser{Name: "lee", Age: 20}) },
+			status: http.StatusOK,
+			want:   &testUser{Name: "lee", Age: 21},
+		},
+		{
+			name:   "post json missing required field",
+			do:     func() *TestResponse { return client.PostJSON("/api/user", map[string]any{"age": 20}) },
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "post json out of range",
+			do:     func() *TestResponse { return client.PostJSON("/api/user", &testUser{Name: "lee", Age: 200}) },
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "get with path and query",
+			do:     func() *TestResponse { return client.Get("/api/user/7", url.Values{"name": {"lee"}}) },
+			status: http.StatusOK,
+			want:   &testUser{Name: "lee-7", Age: 18},
+		},
+		{
+			name:   "get missing query",
+			do:     func() *TestResponse { return client.Get("/api/user/7", nil) },
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "route not found",
+			do:     func() *TestResponse { return client.Get("/api/users", nil) },
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.do().AssertStatus(t, tt.status)
+			if tt.want == nil {
+				return
+			}
+
+			got := &testUser{}
+			if err := resp.Decode(got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.Name != tt.want.Name || got.Age != tt.want.Age {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTestClientValidationDetail(t *testing.T) {
+	resp := NewTestClient(newTestUserApp()).
+		PostJSON("/api/user", map[string]any{"age": 20}).
+		AssertStatus(t, http.StatusUnprocessableEntity)
+
+	body := &struct {
+		Detail []struct {
+			Loc []string `json:"loc"`
+		} `json:"detail"`
+	}{}
+	if err := resp.JSON(body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Detail) != 1 || len(body.Detail[0].Loc) != 2 || body.Detail[0].Loc[0] != "body" {
+		t.Errorf("expected one error located in body, got %s", resp.Body)
+	}
+}
+
+func TestTestClientOpenAPI(t *testing.T) {
+	resp := NewTestClient(newTestUserApp()).OpenAPI().AssertStatus(t, http.StatusOK)
+
+	doc := map[string]any{}
+	if err := resp.JSON(&doc); err != nil {
+		t.Fatalf("decode openapi: %v", err)
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	for _, path := range []string{"/api/user", "/api/user/{id}"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("expected path %s in openapi document", path)
+		}
+	}
+}
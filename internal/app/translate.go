@@ -0,0 +1,209 @@
+package app
+
+import (
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entrans "github.com/go-playground/validator/v10/translations/en"
+	zhtrans "github.com/go-playground/validator/v10/translations/zh"
+	"github.com/gofiber/fiber/v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LanguageEN      = "en"  // 英文
+	LanguageZH      = "zh"  // 简体中文
+	ValidateMsgTag  = "msg" // 自定义校验错误信息标签, 如: msg:"用户名不能为空", 亦可通过 msg_zh 和 msg_en 标签指定某一语言的错误信息
+	DefaultLanguage = LanguageEN
+)
+
+const ( // 参数校验错误信息
+	msgPathRequired         = "path must not be empty"
+	msgQueryRequired        = "query must not be empty"
+	msgHeaderRequired       = "header must not be empty"
+	msgCookieRequired       = "cookie must not be empty"
+	msgUnsupportedMediaType = "unsupported media type"
+)
+
+// messagesZH godantic 及参数校验产生的错误信息的中文翻译, 以英文错误信息为键
+var messagesZH = map[string]string{
+	godantic.FieldRequired:        "字段不能为空",
+	godantic.ValueTypeMismatch:    "值的类型不匹配",
+	godantic.ValueNotInEnum:       "值不是有效的枚举成员",
+	godantic.ValueLessThan:        "值必须大于或等于限定值",
+	godantic.ValueGreaterThan:     "值必须小于或等于限定值",
+	godantic.StringNotMatch:       "字符串与正则表达式不匹配",
+	godantic.ConstraintFailed:     "值不满足校验约束",
+	godantic.QueryValueNotInteger: "值不是有效的整数",
+	godantic.QueryValueNotNumber:  "值不是有效的数字",
+	godantic.QueryValueNotBool:    "值无法转换为布尔值",
+	godantic.QueryValueNotTime:    "值不是有效的时间",
+	godantic.QueryValueOverflow:   "值超出范围",
+	godantic.FileTooLarge:         "文件大小超出限制",
+	godantic.FileTypeNotAllowed:   "文件类型不被允许",
+	godantic.JsonInvalid:          "无效的json",
+	msgPathRequired:               "路径参数不能为空",
+	msgQueryRequired:              "查询参数不能为空",
+	msgHeaderRequired:             "请求头参数不能为空",
+	msgCookieRequired:             "cookie参数不能为空",
+	msgUnsupportedMediaType:       "不支持的请求体格式",
+}
+
+// paramRequiredMessage 参数缺失时的错误信息
+func paramRequiredMessage(in string) string {
+	switch in {
+	case godantic.InPath:
+		return msgPathRequired
+	case godantic.InHeader:
+		return msgHeaderRequired
+	case godantic.InCookie:
+		return msgCookieRequired
+	default:
+		return msgQueryRequired
+	}
+}
+
+// newTranslator 创建校验错误信息翻译器, 为 validator 注册中文和英文翻译, 并注册 godantic 错误信息的翻译
+func newTranslator(v *validator.Validate) *ut.UniversalTranslator {
+	uni := ut.New(en.New(), en.New(), zh.New())
+
+	if trans, found := uni.GetTranslator(LanguageEN); found {
+		_ = entrans.RegisterDefaultTranslations(v, trans)
+		for msg := range messagesZH {
+			_ = trans.Add(msg, msg, false)
+		}
+	}
+	if trans, found := uni.GetTranslator(LanguageZH); found {
+		_ = zhtrans.RegisterDefaultTranslations(v, trans)
+		for msg, text := range messagesZH {
+			_ = trans.Add(msg, text, false)
+		}
+	}
+	return uni
+}
+
+// newService 创建服务依赖, 并初始化请求体验证器和校验错误信息翻译器
+func newService(svc CustomService) *Service {
+	v := validator.New()
+	return &Service{ctx: svc, validate: v, translator: newTranslator(v), language: DefaultLanguage}
+}
+
+// acceptLanguages 解析 Accept-Language 请求头, 按权重由高到低返回语言标签,
+// 对于 zh-CN 这类带有地区的标签, 会同时返回 zh_CN 和 zh
+func acceptLanguages(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+	langs := make([]lang, 0)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if f, err := strconv.ParseFloat(params[2:], 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			langs = append(langs, lang{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	tags := make([]string, 0, len(langs)*2)
+	for _, l := range langs {
+		tags = append(tags, strings.ReplaceAll(l.tag, "-", "_"))
+		if base, _, found := strings.Cut(l.tag, "-"); found {
+			tags = append(tags, base)
+		}
+	}
+	return tags
+}
+
+// Language 当前请求的校验错误信息语言, 由 Accept-Language 请求头决定, 不支持时为应用的默认语言
+func (c *Context) Language() string {
+	if c.ec != nil {
+		for _, tag := range acceptLanguages(c.ec.Get(fiber.HeaderAcceptLanguage)) {
+			if _, found := c.app.service.translator.GetTranslator(tag); found {
+				return strings.ToLower(tag)
+			}
+		}
+	}
+	return c.app.service.language
+}
+
+// translateError 翻译校验错误信息, 字段的 msg_{lang} 和 msg 标签优先于翻译器
+//
+//	@param	rt		reflect.Type			被校验的结构体类型
+//	@param	fe		validator.FieldError	校验错误
+//	@param	lang	string					语言
+func (s *Service) translateError(rt reflect.Type, fe validator.FieldError, lang string) string {
+	if field, found := structFieldByNamespace(rt, fe.StructNamespace()); found {
+		if msg := field.Tag.Get(ValidateMsgTag + "_" + lang); msg != "" {
+			return msg
+		}
+		if msg := field.Tag.Get(ValidateMsgTag); msg != "" {
+			return msg
+		}
+	}
+
+	if trans, found := s.translator.GetTranslator(lang); found {
+		return fe.Translate(trans)
+	}
+	return fe.Error()
+}
+
+// translateMessage 翻译 godantic 和参数校验产生的错误信息, 未注册翻译的信息原样返回
+//
+//	@param	msg		string	英文错误信息
+//	@param	lang	string	语言
+func (s *Service) translateMessage(msg, lang string) string {
+	if trans, found := s.translator.GetTranslator(lang); found {
+		if text, err := trans.T(msg); err == nil && text != "" {
+			return text
+		}
+	}
+	return msg
+}
+
+// validationError 将 godantic 的校验错误转换为接口错误类型, 并依据当前请求的语言翻译错误信息
+func (c *Context) validationError(e *godantic.ValidationError) *ValidationError {
+	ve := validationErrorFrom(e)
+	ve.Msg = c.app.service.translateMessage(ve.Msg, c.Language())
+	return ve
+}
+
+// structFieldByNamespace 依据 validator 的字段命名空间查找结构体字段, 如: User.Address.City 或 User.Items[0].Name
+func structFieldByNamespace(rt reflect.Type, namespace string) (reflect.StructField, bool) {
+	var field reflect.StructField
+	parts := strings.Split(namespace, ".")
+	if len(parts) < 2 { // 首个元素为结构体名称
+		return field, false
+	}
+
+	for _, part := range parts[1:] {
+		if i := strings.IndexByte(part, '['); i >= 0 { // 数组或map元素
+			part = part[:i]
+		}
+		for rt.Kind() == reflect.Pointer || rt.Kind() == reflect.Slice || rt.Kind() == reflect.Array || rt.Kind() == reflect.Map {
+			rt = rt.Elem()
+		}
+		if rt.Kind() != reflect.Struct {
+			return field, false
+		}
+		f, found := rt.FieldByName(part)
+		if !found {
+			return field, false
+		}
+		field, rt = f, f.Type
+	}
+	return field, true
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+)
+
+type testPageQuery struct {
+	godantic.QueryModel
+	Page int `json:"page" validate:"required" lte:"100"`
+}
+
+type testTokenHeader struct {
+	godantic.HeaderModel
+	Token string `json:"X-Token" validate:"required"`
+}
+
+func TestTranslateGodanticMessages(t *testing.T) {
+	app := NewFlaskGo("test", "1.0.0", false, nil)
+	router := APIRouter("/api", nil)
+	router.GET("/items", godantic.String, "列表", func(c *Context) *Response {
+		return c.StringResponse("ok")
+	}, &testPageQuery{}, &testTokenHeader{})
+	router.POST("/user", &testUser{}, &testUser{}, "创建用户", func(c *Context) *Response {
+		user := &testUser{}
+		if resp := c.ShouldBindJSON(user); resp != nil {
+			return resp
+		}
+		return c.OKResponse(user)
+	})
+	app.IncludeRouter(router)
+	client := NewTestClient(app)
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		header string
+		want   string
+	}{
+		{"query required en", httptest.NewRequest(http.MethodGet, "/api/items", nil), "X-Token: t", "query must not be empty"},
+		{"query required zh", httptest.NewRequest(http.MethodGet, "/api/items", nil), "X-Token: t", "查询参数不能为空"},
+		{"header required zh", httptest.NewRequest(http.MethodGet, "/api/items?page=1", nil), "", "请求头参数不能为空"},
+		{"query not integer zh", httptest.NewRequest(http.MethodGet, "/api/items?page=x", nil), "X-Token: t", "值不是有效的整数"},
+		{"query greater than zh", httptest.NewRequest(http.MethodGet, "/api/items?page=101", nil), "X-Token: t", "值必须小于或等于限定值"},
+		{"query greater than en", httptest.NewRequest(http.MethodGet, "/api/items?page=101", nil), "X-Token: t", godantic.ValueGreaterThan},
+		{"invalid json zh", httptest.NewRequest(http.MethodPost, "/api/user", strings.NewReader(`{"name":`)), "", "无效的json"},
+		{"json type mismatch zh", httptest.NewRequest(http.MethodPost, "/api/user", strings.NewReader(`{"name":1}`)), "", "值的类型不匹配"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.HasSuffix(tt.name, " zh") {
+				tt.req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+			}
+			if tt.req.Method == http.MethodPost {
+				tt.req.Header.Set("Content-Type", "application/json")
+			}
+			if key, value, found := strings.Cut(tt.header, ": "); found {
+				tt.req.Header.Set(key, value)
+			}
+
+			resp := client.Do(tt.req).AssertStatus(t, http.StatusUnprocessableEntity)
+			body := &HTTPValidationError{}
+			if err := resp.JSON(body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Detail) != 1 || body.Detail[0].Msg != tt.want {
+				t.Errorf("expected message %q, got %s", tt.want, resp.Body)
+			}
+		})
+	}
+}
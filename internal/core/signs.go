@@ -7,29 +7,49 @@ const (
 	HotSwitchSigint = 30 // 热调试开关
 )
 
-var (
-	BaseRoutesDisabled       = false            // 禁用基础路由
-	SwaggerDisabled          = false            // 禁用文档自动生成
-	RequestValidateDisabled  = true             // 禁用请求体自动验证
-	ResponseValidateDisabled = false            // 禁用返回体自动验证
-	MultipleProcessDisabled  = true             // 禁用多进程
-	ShutdownWithTimeout      = 20 * time.Second // 关机前的最大等待时间
-	DumpPIDEnabled           = false            // 是否记录PID
-)
+// Flags 应用的内部标志量, 每一个应用实例持有一份独立的标志量
+type Flags struct {
+	Debug                    bool          // 是否开启调试模式
+	BaseRoutesDisabled       bool          // 禁用基础路由
+	SwaggerDisabled          bool          // 禁用文档自动生成
+	RequestValidateDisabled  bool          // 禁用请求体自动验证
+	ResponseValidateDisabled bool          // 禁用返回体自动验证
+	MultipleProcessDisabled  bool          // 禁用多进程
+	ShutdownWithTimeout      time.Duration // 关机前的最大等待时间
+	DumpPIDEnabled           bool          // 是否记录PID
+	QueryBindingEnabled      bool          // 是否自动绑定查询参数结构体
+	ProblemDetailsEnabled    bool          // 错误响应是否采用 RFC 7807 格式
+}
 
-var isDebug bool = false
+// NewFlags 创建默认的标志量
+//
+//	@param	debug	bool	是否开启调试模式
+func NewFlags(debug bool) *Flags {
+	return &Flags{
+		Debug:                    debug,
+		BaseRoutesDisabled:       false,
+		SwaggerDisabled:          false,
+		RequestValidateDisabled:  true,
+		ResponseValidateDisabled: false,
+		MultipleProcessDisabled:  true,
+		ShutdownWithTimeout:      20 * time.Second,
+		DumpPIDEnabled:           false,
+		QueryBindingEnabled:      false,
+		ProblemDetailsEnabled:    false,
+	}
+}
 
-func IsDebug() bool   { return isDebug }
-func SetMode(md bool) { isDebug = md }
-func GetMode(short ...bool) string {
+func (f *Flags) IsDebug() bool   { return f.Debug }
+func (f *Flags) SetMode(md bool) { f.Debug = md }
+func (f *Flags) GetMode(short ...bool) string {
 	if len(short) > 0 {
-		if isDebug {
+		if f.Debug {
 			return "Dev"
 		} else {
 			return "Prod"
 		}
 	} else {
-		if isDebug {
+		if f.Debug {
 			return "Development Environment"
 		} else {
 			return "Production Environment"
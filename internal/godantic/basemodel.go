@@ -2,9 +2,9 @@ package godantic
 
 import (
 	"errors"
+	"github.com/Chendemo12/functools/helper"
 	"github.com/Chendemo12/functools/structfuncs"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -20,18 +20,17 @@ type Field struct {
 	Default     string            `json:"default" description:"默认值"` // 暂时仅限 swagger 使用，后期也应在字段校验时使用
 	ItemRef     string            `description:"子元素类型, 仅Type=array/object时有效"`
 	OType       OpenApiDataType   `json:"otype,omitempty" description:"openaapi 数据类型"`
+	Format      string            `json:"format,omitempty" description:"数据格式, 如 binary, 对于数组类型则为子元素的格式"`
 }
 
-// Schema 生成字段的详细描述信息
+// Schema 生成字段的详细描述信息, 字段是否必须由所属模型的 required 数组描述
 //
 //	// 字段为结构体类型
 //
 //	"position_sat": {
 //		"title": "position_sat",
-//		"type": "object"
 //		"description": "position_sat",
-//		"required": false,
-//		"$ref": "#/comonents/schemas/example.PositionGeo",
+//		"allOf": [{"$ref": "#/components/schemas/example.PositionGeo"}]
 //	}
 //
 //	// 字段为数组类型, 数组元素为基本类型
@@ -40,7 +39,6 @@ type Field struct {
 //		"title": "traffic_timeslot",
 //		"type": "array"
 //		"description": "业务时隙编号数组",
-//		"required": false,
 //		"items": {
 //			"type": "integer"
 //		},
@@ -52,83 +50,55 @@ type Field struct {
 //		"title": "Detail",
 //		"type": "array"
 //		"description": "Detail",
-//		"required": true,
 //		"items": {
-//			"$ref": "#/comonents/schemas/ValidationError"
+//			"$ref": "#/components/schemas/ValidationError"
 //		},
 //	}
 func (f *Field) Schema() (m map[string]any) {
 	// 最基础的属性，必须
 	m = dict{
-		"name":        f.SchemaName(true),
 		"title":       f.Title,
 		"type":        f.OType,
-		"required":    f.IsRequired(),
 		"description": f.SchemaDesc(),
 	}
 	// 生成默认值
 	if f.Default != "" {
-		m["default"] = f.Default
+		m["default"] = StringToOType(f.Default, f.OType)
 	}
 	// 生成字段的枚举值
-	if es := QueryFieldTag(f.Tag, "oneof", ""); es != "" {
-		m["enum"] = strings.Split(es, " ")
+	if es := SchemaEnum(f.Tag); len(es) > 0 {
+		enum := make([]any, len(es))
+		for i := range es {
+			enum[i] = StringToOType(es[i], f.OType)
+		}
+		m["enum"] = enum
+	}
+	// 生成字段的校验约束, 如最大最小值和长度
+	for k, v := range SchemaConstraints(f.Tag, f.OType) {
+		m[k] = v
+	}
+
+	if f.Format != "" && f.OType != ArrayType {
+		m["format"] = f.Format
 	}
 
 	// 为不同的字段类型生成相应的描述
 	switch f.OType {
-	case IntegerType, NumberType: // 生成数字类型的最大最小值
-		if lt := QueryFieldTag(f.Tag, "lt", ""); lt != "" {
-			m["maximum"], _ = strconv.Atoi(lt)
-		}
-		if gt := QueryFieldTag(f.Tag, "gt", ""); gt != "" {
-			m["minimum"], _ = strconv.Atoi(gt)
-		}
-
-		if lt := QueryFieldTag(f.Tag, "lte", ""); lt != "" {
-			m["exclusiveMaximum"], _ = strconv.Atoi(lt)
-		}
-		if gt := QueryFieldTag(f.Tag, "gte", ""); gt != "" {
-			m["exclusiveMinimum"], _ = strconv.Atoi(gt)
-		}
-		// 存在多个标记
-		if lt := QueryFieldTag(f.Tag, "max", ""); lt != "" {
-			m["maximum"], _ = strconv.Atoi(lt)
-		}
-		if gt := QueryFieldTag(f.Tag, "min", ""); gt != "" {
-			m["minimum"], _ = strconv.Atoi(gt)
-		}
-
-	case StringType: // 生成字符串类型的最大最小长度
-		if lt := QueryFieldTag(f.Tag, "max", ""); lt != "" {
-			m["maxLength"], _ = strconv.Atoi(lt)
-		}
-		if gt := QueryFieldTag(f.Tag, "min", ""); gt != "" {
-			m["minLength"], _ = strconv.Atoi(gt)
-		}
-
 	case ArrayType:
 		// 为数组类型生成子类型描述
-		if f.ItemRef != "" {
-			if !strings.HasPrefix(f.ItemRef, RefPrefix) { // 数组子元素为关联类型
-				m["items"] = map[string]string{"$ref": RefPrefix + f.ItemRef}
-			} else { // 子元素为基本数据类型
-				m["items"] = map[string]string{"type": f.ItemRef}
-			}
+		if f.Format != "" { // 子元素为带格式的字符串, 如上传文件
+			m["items"] = map[string]string{"type": string(StringType), "format": f.Format}
+		} else if f.ItemRef != "" {
+			m["items"] = itemSchema(f.ItemRef)
 		} else { // 缺省为string
 			m["items"] = map[string]OpenApiDataType{"type": StringType}
 		}
-		// 限制数组的长度
-		if lt := QueryFieldTag(f.Tag, "max", ""); lt != "" {
-			m["maxLength"], _ = strconv.Atoi(lt)
-		}
-		if gt := QueryFieldTag(f.Tag, "min", ""); gt != "" {
-			m["minLength"], _ = strconv.Atoi(gt)
-		}
 
 	case ObjectType:
 		if f.ItemRef != "" { // 字段类型为自定义结构体，生成关联类型，此内部结构体已注册
-			m["$ref"] = RefPrefix + f.ItemRef
+			// $ref 不允许与其他属性并列, 以 allOf 引用关联类型
+			delete(m, "type")
+			m["allOf"] = []map[string]string{{RefName: RefPrefix + f.ItemRef}}
 		}
 
 	default:
@@ -137,6 +107,28 @@ func (f *Field) Schema() (m map[string]any) {
 	return
 }
 
+// itemSchema 数组子元素的文档, 基本数据类型直接以 type 描述, 自定义结构体则生成关联类型
+//
+//	@param	ref	string	子元素类型, 如: string, int, main.Step 或 []main.Step
+func itemSchema(ref string) map[string]any {
+	ref = strings.TrimLeft(ref, "*")
+	if strings.HasPrefix(ref, "[]") { // 子元素同样为数组
+		return map[string]any{"type": ArrayType, "items": itemSchema(ref[2:])}
+	}
+
+	switch ref {
+	case String.SchemaName(true), "string":
+		return map[string]any{"type": StringType}
+	case Bool.SchemaName(true), "bool":
+		return map[string]any{"type": BoolType}
+	case Int.SchemaName(true), "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return map[string]any{"type": IntegerType}
+	case Float.SchemaName(true), "float32", "float64":
+		return map[string]any{"type": NumberType}
+	}
+	return map[string]any{RefName: RefPrefix + ref}
+}
+
 // SchemaName swagger文档字段名
 func (f *Field) SchemaName(exclude ...bool) string {
 	if len(exclude) > 0 {
@@ -192,13 +184,12 @@ type BaseModel struct {
 //		"title": "examle.MyTimeslot",
 //		"type": "object"
 //		"description": "examle.mytimeslot",
-//		"required": [],
+//		"required": ["control_timeslot"],
 //		"properties": {
 //			"control_timeslot": {
 //				"title": "control_timeslot",
 //				"type": "array"
 //				"description": "控制时隙编号数组",
-//				"required": false,
 //				"items": {
 //					"type": "integer"
 //				},
@@ -207,7 +198,6 @@ type BaseModel struct {
 //				"title": "superframe_count",
 //				"type": "integer"
 //				"description": "超帧计数",
-//				"required": false,
 //			},
 //		},
 //	},
@@ -234,7 +224,10 @@ func (b *BaseModel) Schema() (m map[string]any) {
 		}
 	}
 
-	m["required"], m["properties"] = required, properties
+	if len(required) > 0 { // required 不允许为空数组
+		m["required"] = required
+	}
+	m["properties"] = properties
 
 	return
 }
@@ -366,22 +359,109 @@ func (b *BaseModel) Include(include map[string]any) (m map[string]any) {
 	return b.Dict([]string{}, include)
 }
 
-// Validate 检验实例是否符合tag要求
+// Validate 检验实例是否符合tag要求, 首先校验 required, oneof, gte, lte 和 pattern 标签,
+// 通过后再以 validator 校验 validate 标签(如: min, max 和 email), 与请求体的校验规则一致;
+// 模型的元信息依据实例的类型获取, 未注册的模型会被自动注册
+//
+//	@param	stc	any	待校验的模型实例, 如: &User{} 或 User{}
+//	@return	[]*ValidationError 校验错误，校验通过则为空
+//
+//	user := &User{Name: "lee"}
+//	if errs := user.Validate(user); len(errs) > 0 { ... }
 func (b *BaseModel) Validate(stc any) []*ValidationError {
-	// TODO: NotImplemented
-	return nil
+	rv := indirectValue(reflect.ValueOf(stc))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return []*ValidationError{newValidationError(nil, ModelNotEmbedded, ObjectType, nil)}
+	}
+	if !rv.CanAddr() { // 以值传递的实例, 复制后校验
+		v := reflect.New(rv.Type())
+		v.Elem().Set(rv)
+		rv = v.Elem()
+	}
+
+	model, ves := modelOf(rv.Addr().Interface())
+	if ves != nil {
+		return ves
+	}
+	if ves = ValidateModel(model, model); len(ves) > 0 {
+		return ves
+	}
+	return ValidateTags(model)
 }
 
-// ParseRaw 从原始字节流中解析结构体对象
-func (b *BaseModel) ParseRaw(stc []byte) []*ValidationError {
-	// TODO: NotImplemented
+// ParseRaw 从原始字节流中解析结构体对象, 解析和校验(同 Validate)均通过后才会覆盖 stc 的值
+// 模型的元信息依据实例的类型获取, 未注册的模型会被自动注册
+//
+//	@param	stc	any		模型的结构体指针, 如: &User{}
+//	@param	raw	[]byte	json字节流
+//	@return	[]*ValidationError 解析或校验错误，通过则为空
+//
+//	user := &User{}
+//	if errs := user.ParseRaw(user, payload); len(errs) > 0 { ... }
+func (b *BaseModel) ParseRaw(stc any, raw []byte) []*ValidationError {
+	rv := reflect.ValueOf(stc)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return []*ValidationError{newValidationError(nil, ModelNotEmbedded, ObjectType, nil)}
+	}
+	model, ves := modelOf(stc)
+	if ves != nil {
+		return ves
+	}
+	meta, _ := model.Metadata()
+
+	v := reflect.New(rv.Elem().Type())
+	if err := helper.DefaultJsonUnmarshal(raw, v.Interface()); err != nil {
+		return []*ValidationError{JsonDecodeError(raw, v.Elem().Type(), err)}
+	}
+	if ves = ValidateModel(model, v.Interface()); len(ves) > 0 {
+		return ves
+	}
+	if ves = ValidateTags(v.Interface()); len(ves) > 0 {
+		return ves
+	}
+
+	rv.Elem().Set(v.Elem())
+	model.SetId(meta.Id()) // 覆盖后模型标识被清空
 	return nil
 }
 
-// Copy 拷贝一个新的空实例对象
-func (b *BaseModel) Copy() any {
-	// TODO: NotImplemented
-	return nil
+// modelOf 获取结构体指针对应的模型, 并依据其类型关联模型的元信息, 未注册的模型会被自动注册
+func modelOf(stc any) (SchemaIface, []*ValidationError) {
+	model, ok := stc.(SchemaIface)
+	if !ok {
+		return nil, []*ValidationError{newValidationError(nil, ModelNotEmbedded, ObjectType, nil)}
+	}
+	if meta := GetMetadata(reflect.TypeOf(stc).Elem().String()); meta != nil {
+		model.SetId(meta.Id())
+	} else if RegisterModel(model) == nil {
+		return nil, []*ValidationError{newValidationError(nil, ModelNotEmbedded, ObjectType, nil)}
+	}
+	return model, nil
+}
+
+// Copy 拷贝一个与 stc 类型相同的新的空实例对象, 无需预先注册模型
+//
+//	@param	stc	any	模型实例, 如: &User{} 或 User{}
+//	@return	any 模型的结构体指针, 如: *User, stc 不是结构体时返回nil
+//
+//	user := &User{Name: "lee"}
+//	empty := user.Copy(user).(*User)
+func (b *BaseModel) Copy(stc any) any {
+	rt := reflect.TypeOf(stc)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	v := reflect.New(rt).Interface()
+	if model, ok := v.(SchemaIface); ok {
+		if meta := GetMetadata(rt.String()); meta != nil {
+			model.SetId(meta.Id())
+		}
+	}
+	return v
 }
 
 // Metadata 获取反射后的字段元信息, 此字段应慎重使用
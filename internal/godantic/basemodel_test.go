@@ -0,0 +1,207 @@
+package godantic
+
+import (
+	"reflect"
+	"testing"
+)
+
+type modelTestUser struct {
+	BaseModel
+	Name string   `json:"name" validate:"required"`
+	Age  int      `json:"age" gte:"0" lte:"150"`
+	Role string   `json:"role" oneof:"admin user"`
+	Tags []string `json:"tags"`
+}
+
+func (m *modelTestUser) SchemaDesc() string { return "用户" }
+
+func TestBaseModelValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		stc  any
+		loc  []string
+		msg  string
+	}{
+		{name: "pointer ok", stc: &modelTestUser{Name: "lee", Age: 20, Role: "admin"}},
+		{name: "value ok", stc: modelTestUser{Name: "lee", Age: 20, Role: "user"}},
+		{name: "optional enum unset", stc: &modelTestUser{Name: "lee"}},
+		{name: "required", stc: &modelTestUser{Age: 20, Role: "user"}, loc: []string{"name"}, msg: FieldRequired},
+		{name: "greater than", stc: &modelTestUser{Name: "lee", Age: 200, Role: "user"}, loc: []string{"age"}, msg: ValueGreaterThan},
+		{name: "less than", stc: modelTestUser{Name: "lee", Age: -1, Role: "user"}, loc: []string{"age"}, msg: ValueLessThan},
+		{name: "enum", stc: &modelTestUser{Name: "lee", Role: "guest"}, loc: []string{"role"}, msg: ValueNotInEnum},
+		{name: "nil", stc: nil, msg: ModelNotEmbedded},
+		{name: "not a model", stc: &struct{ Name string }{}, msg: ModelNotEmbedded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ves := (&modelTestUser{}).Validate(tt.stc)
+			if tt.msg == "" {
+				if len(ves) > 0 {
+					t.Fatalf("expected no errors, got %s: %v", ves[0].Msg, ves[0].Loc)
+				}
+				return
+			}
+			if len(ves) != 1 {
+				t.Fatalf("expected 1 error, got %d", len(ves))
+			}
+			if ves[0].Msg != tt.msg || (tt.loc != nil && !reflect.DeepEqual(ves[0].Loc, tt.loc)) {
+				t.Errorf("expected %q at %v, got %q at %v", tt.msg, tt.loc, ves[0].Msg, ves[0].Loc)
+			}
+		})
+	}
+}
+
+func TestBaseModelParseRaw(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want *modelTestUser
+		msg  string
+	}{
+		{
+			name: "ok", raw: `{"name": "lee", "age": 20, "role": "admin", "tags": ["a"]}`,
+			want: &modelTestUser{Name: "lee", Age: 20, Role: "admin", Tags: []string{"a"}},
+		},
+		{name: "invalid json", raw: `{"name": `, msg: JsonInvalid},
+		{name: "type mismatch", raw: `{"name": "lee", "age": "20"}`, msg: ValueTypeMismatch},
+		{name: "validation", raw: `{"age": 20}`, msg: FieldRequired},
+		{name: "optional enum unset", raw: `{"name": "lee"}`, want: &modelTestUser{Name: "lee"}},
+		{name: "invalid enum", raw: `{"name": "lee", "role": "guest"}`, msg: ValueNotInEnum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 未注册的新实例, 元信息依据类型获取
+			user := &modelTestUser{Name: "origin"}
+			ves := user.ParseRaw(user, []byte(tt.raw))
+
+			if tt.msg != "" {
+				if len(ves) == 0 || ves[0].Msg != tt.msg {
+					t.Fatalf("expected %q, got %v", tt.msg, ves)
+				}
+				if user.Name != "origin" {
+					t.Errorf("expected instance unchanged on error, got %+v", user)
+				}
+				return
+			}
+			if len(ves) > 0 {
+				t.Fatalf("expected no errors, got %s: %v", ves[0].Msg, ves[0].Loc)
+			}
+			if user.Name != tt.want.Name || user.Age != tt.want.Age || user.Role != tt.want.Role ||
+				!reflect.DeepEqual(user.Tags, tt.want.Tags) {
+				t.Errorf("expected %+v, got %+v", tt.want, user)
+			}
+			if meta, err := user.Metadata(); err != nil || meta.Id() != reflect.TypeOf(user).Elem().String() {
+				t.Errorf("expected metadata to be associated after parse, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBaseModelParseRawNotPointer(t *testing.T) {
+	user := modelTestUser{}
+	if ves := user.ParseRaw(user, []byte(`{"name": "lee"}`)); len(ves) != 1 || ves[0].Msg != ModelNotEmbedded {
+		t.Errorf("expected %q, got %v", ModelNotEmbedded, ves)
+	}
+}
+
+type modelTestAccount struct {
+	BaseModel
+	Email string   `json:"email" validate:"required,email"`
+	Nick  string   `json:"nick" validate:"omitempty,min=2,max=8"`
+	Tags  []string `json:"tags" validate:"dive,min=1"`
+	Role  string   `json:"role" oneof:"admin user"`
+}
+
+func TestBaseModelValidateTags(t *testing.T) {
+	tests := []struct {
+		name string
+		stc  *modelTestAccount
+		loc  []string
+		msg  string
+		tag  string
+	}{
+		{name: "ok", stc: &modelTestAccount{Email: "lee@example.com", Nick: "lee", Tags: []string{"a"}}},
+		{name: "required", stc: &modelTestAccount{}, loc: []string{"email"}, msg: FieldRequired},
+		{name: "email", stc: &modelTestAccount{Email: "lee"}, loc: []string{"email"}, msg: ConstraintFailed, tag: "email"},
+		{name: "min", stc: &modelTestAccount{Email: "lee@example.com", Nick: "l"}, loc: []string{"nick"}, msg: ConstraintFailed, tag: "min"},
+		{name: "max", stc: &modelTestAccount{Email: "lee@example.com", Nick: "lee-lee-lee"}, loc: []string{"nick"}, msg: ConstraintFailed, tag: "max"},
+		{name: "dive", stc: &modelTestAccount{Email: "lee@example.com", Tags: []string{"a", ""}}, loc: []string{"tags", "1"}, msg: ConstraintFailed, tag: "min"},
+		// 独立标签的约束首先校验
+		{name: "oneof before validate tags", stc: &modelTestAccount{Email: "lee", Role: "guest"}, loc: []string{"role"}, msg: ValueNotInEnum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ves := tt.stc.Validate(tt.stc)
+			if tt.msg == "" {
+				if len(ves) > 0 {
+					t.Fatalf("expected no errors, got %s: %v", ves[0].Msg, ves[0].Loc)
+				}
+				return
+			}
+			if len(ves) != 1 || ves[0].Msg != tt.msg || !reflect.DeepEqual(ves[0].Loc, tt.loc) {
+				t.Fatalf("expected %q at %v, got %v", tt.msg, tt.loc, ves)
+			}
+			if tt.tag != "" && ves[0].Ctx["tag"] != tt.tag {
+				t.Errorf("expected tag %q, got %v", tt.tag, ves[0].Ctx["tag"])
+			}
+		})
+	}
+
+	account := &modelTestAccount{Email: "origin@example.com"}
+	if ves := account.ParseRaw(account, []byte(`{"email": "lee", "nick": "lee"}`)); len(ves) != 1 || ves[0].Msg != ConstraintFailed {
+		t.Errorf("expected ParseRaw to run validate tags, got %v", ves)
+	}
+	if account.Email != "origin@example.com" {
+		t.Errorf("expected instance unchanged on error, got %+v", account)
+	}
+}
+
+func TestBaseModelCopy(t *testing.T) {
+	type unregistered struct {
+		BaseModel
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name string
+		stc  any
+		want reflect.Type
+	}{
+		{name: "unregistered pointer", stc: &unregistered{Name: "lee"}, want: reflect.TypeOf(&unregistered{})},
+		{name: "unregistered value", stc: unregistered{Name: "lee"}, want: reflect.TypeOf(&unregistered{})},
+		{name: "registered", stc: &modelTestUser{Name: "lee"}, want: reflect.TypeOf(&modelTestUser{})},
+		{name: "nil", stc: nil},
+		{name: "not a struct", stc: "lee"},
+	}
+	RegisterModel(&modelTestUser{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := (&BaseModel{}).Copy(tt.stc)
+			if tt.want == nil {
+				if v != nil {
+					t.Fatalf("expected nil, got %#v", v)
+				}
+				return
+			}
+			if reflect.TypeOf(v) != tt.want {
+				t.Fatalf("expected %s, got %T", tt.want, v)
+			}
+			rv := reflect.ValueOf(v).Elem()
+			for i := 1; i < rv.NumField(); i++ { // 首个字段为 BaseModel, 可能已关联模型标识
+				if !rv.Field(i).IsZero() {
+					t.Errorf("expected a zero %s, got %#v", tt.want, v)
+				}
+			}
+		})
+	}
+
+	if user, ok := (&modelTestUser{}).Copy(&modelTestUser{}).(*modelTestUser); !ok {
+		t.Errorf("expected *modelTestUser")
+	} else if meta, err := user.Metadata(); err != nil || meta.Id() != reflect.TypeOf(user).Elem().String() {
+		t.Errorf("expected the copy of a registered model to be associated with its metadata, got %v", err)
+	}
+}
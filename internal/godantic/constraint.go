@@ -0,0 +1,169 @@
+package godantic
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 字段标签与 OpenApi 数据格式的对应关系, 如: validate:"email" -> format: email
+var validatorFormats = map[string]string{
+	"email":         "email",
+	"url":           "uri",
+	"uri":           "uri",
+	"http_url":      "uri",
+	"uuid":          "uuid",
+	"uuid3":         "uuid",
+	"uuid4":         "uuid",
+	"uuid5":         "uuid",
+	"uuid_rfc4122":  "uuid",
+	"uuid4_rfc4122": "uuid",
+	"ipv4":          "ipv4",
+	"ipv6":          "ipv6",
+	"hostname":      "hostname",
+	"fqdn":          "hostname",
+	"datetime":      "date-time",
+	"base64":        "byte",
+}
+
+// 字段标签与正则表达式的对应关系, 如: validate:"alpha" -> pattern: ^[a-zA-Z]+$
+var validatorPatterns = map[string]string{
+	"alpha":       "^[a-zA-Z]+$",
+	"alphanum":    "^[a-zA-Z0-9]+$",
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":      "^[0-9]+$",
+	"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+	"lowercase":   "^[^A-Z]*$",
+	"uppercase":   "^[^a-z]*$",
+}
+
+// SchemaConstraints 从字段标签中提取 OpenApi 文档的校验约束, 同时支持独立标签(如: gte:"1", pattern:"^[a-z]+$")
+// 和 validator 的 validate/binding 标签(如: validate:"min=1,max=10,email"), 后者会覆盖前者;
+// 对于数字类型生成 minimum/maximum, 字符串类型生成 minLength/maxLength/pattern/format, 数组类型生成 minItems/maxItems
+//
+//	@param	tag		reflect.StructTag	字段标签
+//	@param	otype	OpenApiDataType		字段的数据类型
+//	@return	map[string]any 校验约束, 如: {"minimum": 1, "exclusiveMinimum": true}
+func SchemaConstraints(tag reflect.StructTag, otype OpenApiDataType) map[string]any {
+	m := make(map[string]any)
+
+	for _, name := range []string{"gte", "lte", "gt", "lt", "min", "max", "len"} {
+		if value := QueryFieldTag(tag, name, ""); value != "" {
+			setConstraint(m, name, value, otype)
+		}
+	}
+	if pattern := QueryFieldTag(tag, "pattern", ""); pattern != "" && otype == StringType {
+		m["pattern"] = pattern
+	}
+
+	for _, label := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(QueryFieldTag(tag, label, ""), ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if strings.ContainsRune(name, '|') { // 或条件无法表示为文档约束
+				continue
+			}
+			setConstraint(m, name, value, otype)
+		}
+	}
+
+	return m
+}
+
+// SchemaEnum 从字段标签中提取枚举值, 独立标签 oneof:"a b" 优先于 validate:"oneof=a b"
+func SchemaEnum(tag reflect.StructTag) []string {
+	if es := QueryFieldTag(tag, "oneof", ""); es != "" {
+		return strings.Fields(es)
+	}
+	for _, label := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(QueryFieldTag(tag, label, ""), ",") {
+			if name, value, found := strings.Cut(strings.TrimSpace(rule), "="); found && name == "oneof" {
+				return strings.Fields(value)
+			}
+		}
+	}
+	return nil
+}
+
+// setConstraint 将一个校验规则转换为文档约束
+func setConstraint(m map[string]any, name, value string, otype OpenApiDataType) {
+	switch otype {
+	case IntegerType, NumberType:
+		limit, ok := parseLimit(value)
+		if !ok {
+			return
+		}
+		switch name {
+		case "gte", "min":
+			m["minimum"] = limit
+		case "lte", "max":
+			m["maximum"] = limit
+		case "gt":
+			m["minimum"], m["exclusiveMinimum"] = limit, true
+		case "lt":
+			m["maximum"], m["exclusiveMaximum"] = limit, true
+		case "len", "eq":
+			m["minimum"], m["maximum"] = limit, limit
+		}
+
+	case StringType, ArrayType:
+		minKey, maxKey := "minLength", "maxLength"
+		if otype == ArrayType {
+			minKey, maxKey = "minItems", "maxItems"
+		}
+
+		if format, ok := validatorFormats[name]; ok && otype == StringType {
+			m["format"] = format
+			return
+		}
+		if pattern, ok := validatorPatterns[name]; ok && otype == StringType {
+			m["pattern"] = pattern
+			return
+		}
+		switch name {
+		case "startswith":
+			m["pattern"] = "^" + regexp.QuoteMeta(value)
+			return
+		case "endswith":
+			m["pattern"] = regexp.QuoteMeta(value) + "$"
+			return
+		case "contains":
+			m["pattern"] = regexp.QuoteMeta(value)
+			return
+		}
+
+		length, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length < 0 {
+			return
+		}
+		switch name {
+		case "gte", "min":
+			m[minKey] = length
+		case "lte", "max":
+			m[maxKey] = length
+		case "gt":
+			m[minKey] = length + 1
+		case "lt":
+			if length > 0 {
+				m[maxKey] = length - 1
+			}
+		case "len":
+			m[minKey], m[maxKey] = length, length
+		}
+	}
+}
+
+// parseLimit 将约束值转换为数字, 整数保持原有精度
+func parseLimit(value string) (any, bool) {
+	value = strings.TrimSpace(value)
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i, true
+	}
+	if u, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return u, true
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
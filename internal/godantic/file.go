@@ -0,0 +1,193 @@
+package godantic
+
+import (
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const BinaryFormat = "binary"
+
+const ( // 文件校验错误信息
+	FileTooLarge       = "file size exceeds the limit"
+	FileTypeNotAllowed = "file type is not allowed"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+	fileModelType   = reflect.TypeOf(FileModel{})
+)
+
+// MultipartIface 文件上传模型, 作为请求体时其格式为 multipart/form-data
+type MultipartIface interface {
+	SchemaIface
+	// IsMultipart 请求体格式是否是 multipart/form-data
+	IsMultipart() bool
+}
+
+// FileModel 文件上传模型基类, 嵌入此基类的结构体作为请求体时, 请求体格式为 multipart/form-data
+// 其中类型为 *multipart.FileHeader 的字段作为单个上传文件, 类型为 []*multipart.FileHeader 的字段作为多个上传文件,
+// 其余字段均作为表单字段
+//
+// 上传文件支持以下标签:
+//
+//	max_size:"1048576"				单个文件的最大字节数
+//	mime:"image/png image/*"		允许的文件类型, 多个类型以空格分隔
+//
+//	type Avatar struct {
+//		godantic.FileModel
+//		Image  *multipart.FileHeader   `json:"image" max_size:"1048576" mime:"image/*" validate:"required"`
+//		Photos []*multipart.FileHeader `json:"photos" mime:"image/png image/jpeg"`
+//		UserId int                     `json:"user_id" validate:"required"`
+//	}
+type FileModel struct {
+	BaseModel
+}
+
+// IsMultipart 请求体格式是否是 multipart/form-data
+func (f *FileModel) IsMultipart() bool { return true }
+
+// IsMultipart 判断一个模型是否是文件上传模型
+func IsMultipart(model SchemaIface) bool {
+	if m, ok := model.(MultipartIface); ok {
+		return m.IsMultipart()
+	}
+	return false
+}
+
+// isFileType 字段是否是上传文件类型
+func isFileType(rt reflect.Type) bool { return rt == fileHeaderType || rt == fileHeadersType }
+
+// BindMultipart 将 multipart/form-data 表单绑定到结构体, 并校验文件的大小和类型
+// 表单字段的值依据字段类型进行转换和范围校验，对于缺省的表单字段，若定义了 default 标签则以默认值填充
+//
+//	@param	stc		any									结构体指针
+//	@param	values	map[string][]string					表单字段
+//	@param	files	map[string][]*multipart.FileHeader	上传文件
+//	@return	*ValidationError 绑定或校验错误
+func BindMultipart(stc any, values map[string][]string, files map[string][]*multipart.FileHeader) *ValidationError {
+	rv := reflect.ValueOf(stc)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return newValidationError([]string{InBody}, ValueTypeMismatch, ObjectType, map[string]any{"received": rv.Type().String()})
+	}
+
+	return bindMultipartStruct(rv.Elem(), values, files)
+}
+
+func bindMultipartStruct(rv reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader) *ValidationError {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !unicode.IsUpper(rune(field.Name[0])) {
+			continue
+		}
+		if field.Anonymous { // 嵌入结构体，其字段同样作为表单字段
+			if field.Type.Kind() == reflect.Struct && field.Type != fileModelType && field.Name != "BaseModel" {
+				if ve := bindMultipartStruct(rv.Field(i), values, files); ve != nil {
+					return ve
+				}
+			}
+			continue
+		}
+
+		name := QueryJsonName(field.Tag, field.Name)
+		if name == "-" {
+			continue
+		}
+
+		if isFileType(field.Type) { // 上传文件
+			fhs := files[name]
+			if len(fhs) == 0 {
+				if IsFieldRequired(field.Tag) {
+					return newValidationError([]string{InBody, name}, FieldRequired, StringType, nil)
+				}
+				continue
+			}
+
+			for no, fh := range fhs {
+				loc := []string{InBody, name}
+				if field.Type == fileHeadersType {
+					loc = append(loc, strconv.Itoa(no))
+				}
+				if ve := validateFile(field.Tag, fh, loc); ve != nil {
+					return ve
+				}
+			}
+
+			if field.Type == fileHeadersType {
+				rv.Field(i).Set(reflect.ValueOf(fhs))
+			} else {
+				rv.Field(i).Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		}
+
+		// 表单字段
+		qm := NewQModel(field, false)
+		qm.In = InBody
+		vs := make([]string, 0)
+		for _, v := range values[name] {
+			if v != "" {
+				vs = append(vs, v)
+			}
+		}
+		if len(vs) == 0 {
+			if qm.IsRequired() {
+				return newValidationError([]string{InBody, name}, FieldRequired, qm.SchemaType(), nil)
+			}
+			dv := QueryFieldTag(field.Tag, "default", "")
+			if dv == "" {
+				continue
+			}
+			vs = append(vs, dv)
+		}
+
+		v, ve := qm.ParseValue(vs...)
+		if ve != nil {
+			return ve
+		}
+		setFieldValue(rv.Field(i), reflect.ValueOf(v))
+	}
+
+	return nil
+}
+
+// validateFile 校验文件的大小和类型
+func validateFile(tag reflect.StructTag, fh *multipart.FileHeader, loc []string) *ValidationError {
+	if limit := QueryFieldTag(tag, "max_size", ""); limit != "" {
+		size, err := strconv.ParseInt(limit, 10, 64)
+		if err == nil && fh.Size > size {
+			return newValidationError(loc, FileTooLarge, StringType, map[string]any{
+				"limit_value": size, "size": fh.Size, "filename": fh.Filename,
+			})
+		}
+	}
+
+	if allowed := QueryFieldTag(tag, "mime", ""); allowed != "" {
+		mediaType, _, err := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+		if err != nil || !isMIMEAllowed(mediaType, strings.Fields(allowed)) {
+			return newValidationError(loc, FileTypeNotAllowed, StringType, map[string]any{
+				"allowed": strings.Fields(allowed), "content_type": fh.Header.Get("Content-Type"), "filename": fh.Filename,
+			})
+		}
+	}
+
+	return nil
+}
+
+// isMIMEAllowed 文件类型是否允许, 支持通配符, 如: image/*
+func isMIMEAllowed(mediaType string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == "*/*" || strings.EqualFold(a, mediaType) {
+			return true
+		}
+		if strings.HasSuffix(a, "/*") && strings.HasPrefix(strings.ToLower(mediaType), strings.ToLower(a[:len(a)-1])) {
+			return true
+		}
+	}
+	return false
+}
@@ -31,12 +31,12 @@ type DictIface interface {
 type Iface interface {
 	SchemaIface
 	DictIface
-	// Validate 检验实例是否符合tag要求
+	// Validate 检验模型实例 stc 是否符合tag要求
 	Validate(stc any) []*ValidationError
-	// ParseRaw 从原始字节流中解析结构体对象
-	ParseRaw(stc []byte) []*ValidationError
-	// Copy 拷贝一个新的空实例对象
-	Copy() any
+	// ParseRaw 从原始字节流中解析结构体对象到模型实例 stc
+	ParseRaw(stc any, raw []byte) []*ValidationError
+	// Copy 拷贝一个与模型实例 stc 类型相同的新的空实例对象
+	Copy(stc any) any
 }
 
 type QueryParameter interface {
@@ -0,0 +1,283 @@
+package godantic
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const JsonInvalid = "invalid json" // json语法错误信息
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// JsonDecodeError 定位json反序列化错误, 返回错误所在的字段路径, 期望的类型和接收到的值
+// 对于语法错误, 错误定位为请求体本身, 并在 Ctx 中记录错误所在的行列号;
+// 对于类型不匹配, 错误定位为字段的完整路径(以json名称和数组下标表示), 并在 Ctx 中记录 json pointer
+//
+//	@param	data	[]byte			json数据
+//	@param	rt		reflect.Type	反序列化的目标类型
+//	@param	err		error			反序列化错误, 当无法定位时作为错误信息
+//	@param	loc		[]string		错误定位的前缀, 如 "body"
+//	@return	*ValidationError 反序列化错误
+func JsonDecodeError(data []byte, rt reflect.Type, err error, loc ...string) *ValidationError {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if e := dec.Decode(&v); e != nil {
+		return jsonSyntaxError(data, e, loc)
+	}
+	if _, e := dec.Token(); e != io.EOF { // 存在多余的数据
+		return newValidationError(loc, JsonInvalid, ObjectType, map[string]any{
+			"error": "invalid character after top-level value", "offset": dec.InputOffset(),
+		})
+	}
+
+	if ve := matchJsonValue(v, rt, loc, ""); ve != nil {
+		return ve
+	}
+
+	msg := JsonInvalid
+	if err != nil {
+		msg = err.Error()
+	}
+	return newValidationError(loc, msg, ObjectType, nil)
+}
+
+// jsonSyntaxError json语法错误, 记录错误所在的字节偏移量和行列号
+func jsonSyntaxError(data []byte, err error, loc []string) *ValidationError {
+	ctx := map[string]any{"error": err.Error()}
+
+	var se *json.SyntaxError
+	offset := int64(len(data))
+	if errors.As(err, &se) {
+		offset = se.Offset
+	} else if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return newValidationError(loc, JsonInvalid, ObjectType, ctx)
+	}
+	if errors.Is(err, io.EOF) {
+		ctx["error"] = "unexpected end of JSON input"
+	}
+
+	pos := offset // 错误字符的位置, SyntaxError.Offset 包含了错误字符本身
+	if se != nil && pos > 0 {
+		pos--
+	}
+	line, column := 1, 1
+	for i := int64(0); i < pos && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	ctx["offset"], ctx["line"], ctx["column"] = offset, line, column
+
+	return newValidationError(loc, JsonInvalid, ObjectType, ctx)
+}
+
+// matchJsonValue 依据目标类型逐层检查json值, 返回第一个类型不匹配的错误
+//
+//	@param	v		any				json值, 数字为 json.Number
+//	@param	rt		reflect.Type	目标类型
+//	@param	loc		[]string		当前值的定位
+//	@param	pointer	string			当前值的 json pointer
+func matchJsonValue(v any, rt reflect.Type, loc []string, pointer string) *ValidationError {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if v == nil { // null 可赋值给任意类型
+		return nil
+	}
+
+	mismatch := func(expected OpenApiDataType) *ValidationError {
+		return newValidationError(loc, ValueTypeMismatch, expected, map[string]any{
+			"expected": expected, "received": jsonValueType(v), "value": v, "pointer": pointer,
+		})
+	}
+
+	// 自定义反序列化方法, 如: time.Time, 直接以其反序列化方法检查
+	if text, ok := v.(string); ok && reflect.PointerTo(rt).Implements(textUnmarshalerType) {
+		if err := reflect.New(rt).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			ve := mismatch(StringType)
+			ve.Ctx["error"] = err.Error()
+			return ve
+		}
+		return nil
+	}
+	if reflect.PointerTo(rt).Implements(jsonUnmarshalerType) {
+		raw, _ := json.Marshal(v)
+		if err := reflect.New(rt).Interface().(json.Unmarshaler).UnmarshalJSON(raw); err != nil {
+			ve := mismatch(reflectKindToOType(rt.Kind()))
+			ve.Ctx["error"] = err.Error()
+			return ve
+		}
+		return nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Interface:
+		return nil
+
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return mismatch(StringType)
+		}
+
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return mismatch(BoolType)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch(IntegerType)
+		}
+		if _, err := strconv.ParseInt(n.String(), 10, rt.Bits()); err != nil {
+			return mismatch(IntegerType)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch(IntegerType)
+		}
+		if _, err := strconv.ParseUint(n.String(), 10, rt.Bits()); err != nil {
+			return mismatch(IntegerType)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch(NumberType)
+		}
+		if _, err := strconv.ParseFloat(n.String(), rt.Bits()); err != nil {
+			return mismatch(NumberType)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if rt.Elem().Kind() == reflect.Uint8 && rt.Kind() == reflect.Slice { // []byte 为base64字符串
+			if _, ok := v.(string); ok {
+				return nil
+			}
+		}
+		items, ok := v.([]any)
+		if !ok {
+			return mismatch(ArrayType)
+		}
+		for i, item := range items {
+			index := strconv.Itoa(i)
+			if ve := matchJsonValue(item, rt.Elem(), appendLoc(loc, index), pointer+"/"+index); ve != nil {
+				return ve
+			}
+		}
+
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch(ObjectType)
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // 保证错误定位的确定性
+		for _, key := range keys {
+			if ve := matchJsonValue(m[key], rt.Elem(), appendLoc(loc, key), pointer+"/"+EscapeJsonPointer(key)); ve != nil {
+				return ve
+			}
+		}
+
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch(ObjectType)
+		}
+		return matchJsonStruct(m, rt, loc, pointer)
+	}
+
+	return nil
+}
+
+// matchJsonStruct 按结构体字段的定义顺序检查json对象, 嵌入结构体的字段与父结构体同级
+func matchJsonStruct(m map[string]any, rt reflect.Type, loc []string, pointer string) *ValidationError {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && QueryFieldTag(field.Tag, "json", "") == "" {
+				if ve := matchJsonStruct(m, ft, loc, pointer); ve != nil {
+					return ve
+				}
+				continue
+			}
+		}
+		if !unicode.IsUpper(rune(field.Name[0])) {
+			continue
+		}
+
+		name := QueryJsonName(field.Tag, field.Name)
+		if name == "-" {
+			continue
+		}
+		key, found := jsonObjectKey(m, name)
+		if !found {
+			continue
+		}
+		if ve := matchJsonValue(m[key], field.Type, appendLoc(loc, name), pointer+"/"+EscapeJsonPointer(key)); ve != nil {
+			return ve
+		}
+	}
+
+	return nil
+}
+
+// jsonObjectKey 查找字段对应的json键, 与标准库一致, 优先精确匹配, 其次忽略大小写匹配
+func jsonObjectKey(m map[string]any, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// jsonValueType json值的类型
+func jsonValueType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return string(StringType)
+	case bool:
+		return string(BoolType)
+	case json.Number:
+		return string(NumberType)
+	case []any:
+		return string(ArrayType)
+	default:
+		return string(ObjectType)
+	}
+}
+
+// EscapeJsonPointer 按 RFC 6901 转义 json pointer 中的键
+func EscapeJsonPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,228 @@
+package godantic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type jsonTestAddress struct {
+	City string `json:"city"`
+	Zip  int    `json:"zip"`
+}
+
+type jsonTestItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type jsonTestEmbedded struct {
+	Remark string `json:"remark"`
+}
+
+type jsonTestUser struct {
+	jsonTestEmbedded
+	Name     string            `json:"name"`
+	Age      uint8             `json:"age"`
+	Active   bool              `json:"active"`
+	Address  *jsonTestAddress  `json:"address"`
+	Tags     []string          `json:"tags"`
+	Items    []jsonTestItem    `json:"items"`
+	Matrix   [][]int           `json:"matrix"`
+	Labels   map[string]int    `json:"labels"`
+	Birthday time.Time         `json:"birthday"`
+	Extra    any               `json:"extra"`
+	Ignored  int               `json:"-"`
+	Meta     map[string]string `json:"meta,omitempty"`
+}
+
+func TestJsonDecodeErrorTypeMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		loc      []string
+		pointer  string
+		expected OpenApiDataType
+		received string
+		value    any
+	}{
+		{
+			name: "top level", data: `[]`,
+			loc: []string{"body"}, pointer: "", expected: ObjectType, received: "array", value: []any{},
+		},
+		{
+			name: "string field", data: `{"name": 1}`,
+			loc: []string{"body", "name"}, pointer: "/name", expected: StringType, received: "number", value: json.Number("1"),
+		},
+		{
+			name: "bool field", data: `{"active": "yes"}`,
+			loc: []string{"body", "active"}, pointer: "/active", expected: BoolType, received: "string", value: "yes",
+		},
+		{
+			name: "integer overflow", data: `{"age": 256}`,
+			loc: []string{"body", "age"}, pointer: "/age", expected: IntegerType, received: "number", value: json.Number("256"),
+		},
+		{
+			name: "negative unsigned", data: `{"age": -1}`,
+			loc: []string{"body", "age"}, pointer: "/age", expected: IntegerType, received: "number", value: json.Number("-1"),
+		},
+		{
+			name: "embedded field", data: `{"remark": false}`,
+			loc: []string{"body", "remark"}, pointer: "/remark", expected: StringType, received: "boolean", value: false,
+		},
+		{
+			name: "nested object field", data: `{"address": {"city": "x", "zip": "100000"}}`,
+			loc: []string{"body", "address", "zip"}, pointer: "/address/zip", expected: IntegerType, received: "string", value: "100000",
+		},
+		{
+			name: "nested object", data: `{"address": "x"}`,
+			loc: []string{"body", "address"}, pointer: "/address", expected: ObjectType, received: "string", value: "x",
+		},
+		{
+			name: "array index", data: `{"tags": ["a", "b", 3]}`,
+			loc: []string{"body", "tags", "2"}, pointer: "/tags/2", expected: StringType, received: "number", value: json.Number("3"),
+		},
+		{
+			name: "object in array", data: `{"items": [{"name": "a", "price": 1.5}, {"name": "b", "price": "free"}]}`,
+			loc: []string{"body", "items", "1", "price"}, pointer: "/items/1/price", expected: NumberType, received: "string", value: "free",
+		},
+		{
+			name: "nested array", data: `{"matrix": [[1, 2], [3, 4.5]]}`,
+			loc: []string{"body", "matrix", "1", "1"}, pointer: "/matrix/1/1", expected: IntegerType, received: "number", value: json.Number("4.5"),
+		},
+		{
+			name: "map value", data: `{"labels": {"a/b": 1, "c~d": "x"}}`,
+			loc: []string{"body", "labels", "c~d"}, pointer: "/labels/c~0d", expected: IntegerType, received: "string", value: "x",
+		},
+		{
+			name: "text unmarshaler", data: `{"birthday": "yesterday"}`,
+			loc: []string{"body", "birthday"}, pointer: "/birthday", expected: StringType, received: "string", value: "yesterday",
+		},
+		{
+			name: "case insensitive key", data: `{"NAME": true}`,
+			loc: []string{"body", "name"}, pointer: "/NAME", expected: StringType, received: "boolean", value: true,
+		},
+		{
+			name: "case insensitive nested key", data: `{"Address": {"City": []}}`,
+			loc: []string{"body", "address", "city"}, pointer: "/Address/City", expected: StringType, received: "array", value: []any{},
+		},
+	}
+
+	rt := reflect.TypeOf(jsonTestUser{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target jsonTestUser
+			err := json.Unmarshal([]byte(tt.data), &target)
+			if err == nil {
+				t.Fatalf("expected unmarshal error for %s", tt.data)
+			}
+
+			ve := JsonDecodeError([]byte(tt.data), rt, err, InBody)
+			if ve.Msg != ValueTypeMismatch {
+				t.Fatalf("expected message %q, got %q (%v)", ValueTypeMismatch, ve.Msg, ve.Ctx)
+			}
+			if !reflect.DeepEqual(ve.Loc, tt.loc) {
+				t.Errorf("expected loc %v, got %v", tt.loc, ve.Loc)
+			}
+			if ve.Type != string(tt.expected) {
+				t.Errorf("expected type %s, got %s", tt.expected, ve.Type)
+			}
+			if ve.Ctx["pointer"] != tt.pointer {
+				t.Errorf("expected pointer %q, got %q", tt.pointer, ve.Ctx["pointer"])
+			}
+			if ve.Ctx["expected"] != tt.expected {
+				t.Errorf("expected ctx.expected %s, got %v", tt.expected, ve.Ctx["expected"])
+			}
+			if ve.Ctx["received"] != tt.received {
+				t.Errorf("expected ctx.received %s, got %v", tt.received, ve.Ctx["received"])
+			}
+			if !reflect.DeepEqual(ve.Ctx["value"], tt.value) {
+				t.Errorf("expected ctx.value %#v, got %#v", tt.value, ve.Ctx["value"])
+			}
+		})
+	}
+}
+
+func TestJsonDecodeErrorSyntax(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		offset int64
+		line   int
+		column int
+	}{
+		{name: "missing value", data: `{"name": }`, offset: 10, line: 1, column: 10},
+		{name: "second line", data: "{\n  \"name\": \"a\",\n  \"age\" 1\n}", offset: 26, line: 3, column: 9},
+		{name: "trailing comma", data: "{\n\t\"tags\": [\"a\",]\n}", offset: 17, line: 2, column: 15},
+		{name: "unexpected end", data: `{"name": "a"`, offset: 12, line: 1, column: 13},
+		{name: "empty body", data: ``, offset: 0, line: 1, column: 1},
+		{name: "trailing data", data: `{"name": "a"} {}`, offset: 15, line: 1, column: -1},
+	}
+
+	rt := reflect.TypeOf(jsonTestUser{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ve := JsonDecodeError([]byte(tt.data), rt, errors.New("decode failed"), InBody)
+			if ve.Msg != JsonInvalid {
+				t.Fatalf("expected message %q, got %q", JsonInvalid, ve.Msg)
+			}
+			if !reflect.DeepEqual(ve.Loc, []string{InBody}) {
+				t.Errorf("expected loc [body], got %v", ve.Loc)
+			}
+			if ve.Ctx["offset"] != tt.offset {
+				t.Errorf("expected offset %d, got %v", tt.offset, ve.Ctx["offset"])
+			}
+			if tt.column < 0 { // 多余的数据仅记录偏移量
+				return
+			}
+			if ve.Ctx["line"] != tt.line || ve.Ctx["column"] != tt.column {
+				t.Errorf("expected line %d column %d, got line %v column %v (%v)",
+					tt.line, tt.column, ve.Ctx["line"], ve.Ctx["column"], ve.Ctx["error"])
+			}
+		})
+	}
+}
+
+func TestJsonDecodeErrorUnlocated(t *testing.T) {
+	// json本身合法且与类型匹配时, 以原始错误作为错误信息
+	err := fmt.Errorf("custom decode error")
+	ve := JsonDecodeError([]byte(`{"name": "a", "extra": [1, {"x": null}]}`), reflect.TypeOf(jsonTestUser{}), err, InBody)
+	if ve.Msg != err.Error() || !reflect.DeepEqual(ve.Loc, []string{InBody}) {
+		t.Errorf("expected unlocated error %q at [body], got %q at %v", err, ve.Msg, ve.Loc)
+	}
+}
+
+func TestJsonObjectKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []string
+		field string
+		key   string
+		found bool
+	}{
+		{name: "exact", keys: []string{"name"}, field: "name", key: "name", found: true},
+		{name: "upper", keys: []string{"NAME"}, field: "name", key: "NAME", found: true},
+		{name: "mixed", keys: []string{"UserId"}, field: "userid", key: "UserId", found: true},
+		{name: "exact preferred", keys: []string{"Name", "name", "NAME"}, field: "name", key: "name", found: true},
+		{name: "missing", keys: []string{"names"}, field: "name", found: false},
+		{name: "empty object", keys: nil, field: "name", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := make(map[string]any, len(tt.keys))
+			for _, key := range tt.keys {
+				m[key] = nil
+			}
+			key, found := jsonObjectKey(m, tt.field)
+			if found != tt.found || key != tt.key {
+				t.Errorf("expected (%q, %v), got (%q, %v) for keys %s",
+					tt.key, tt.found, key, found, strings.Join(tt.keys, ","))
+			}
+		})
+	}
+}
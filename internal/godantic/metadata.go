@@ -12,6 +12,23 @@ func GetMetadataFactory() *MetaClass   { return metaDataFactory }
 func GetMetadata(pkg string) *Metadata { return metaDataFactory.Get(pkg) }
 func SaveMetadata(data *Metadata)      { metaDataFactory.Set(data) }
 
+// RegisterModel 反射并保存模型的元信息, 路由中的模型会被自动注册,
+// 在路由之外使用模型的 Copy 方法前需先注册, Validate 和 ParseRaw 会依据实例的类型自动注册
+//
+//	@param	model	SchemaIface	模型, 需为结构体指针
+//	@return	*Metadata 模型的元信息
+func RegisterModel(model SchemaIface) *Metadata {
+	if model == nil {
+		return nil
+	}
+	meta := metaDataFactory.Reflect(model)
+	meta.SetDesc(model.SchemaDesc())
+	SaveMetadata(meta)
+	model.SetId(meta.Id())
+
+	return meta
+}
+
 func StructReflect(rt reflect.Type) *Metadata {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
@@ -26,6 +43,7 @@ func StructReflect(rt reflect.Type) *Metadata {
 		fields:      make([]*MetaField, 0),
 		innerFields: make([]*MetaField, 0),
 		oType:       ObjectType,
+		rtype:       rt,
 	}
 
 	ref := ModelReflect{metadata: meta}
@@ -69,6 +87,7 @@ type Metadata struct {
 	names       []string        `description:"结构体名称,包名.结构体名称"`
 	fields      []*MetaField    `description:"结构体字段"`
 	innerFields []*MetaField    `description:"内部字段"`
+	rtype       reflect.Type    `description:"结构体类型"`
 }
 
 // Name 获取结构体名称
@@ -77,6 +96,9 @@ func (m *Metadata) Name() string { return m.names[0] }
 // String 结构体全称：包名+结构体名称
 func (m *Metadata) String() string { return m.names[1] }
 
+// RType 结构体的反射类型
+func (m *Metadata) RType() reflect.Type { return m.rtype }
+
 // Fields 结构体字段
 func (m *Metadata) Fields() []*MetaField { return m.fields }
 
@@ -115,7 +137,10 @@ func (m *Metadata) Schema() map[string]any {
 		}
 	}
 
-	schema["required"], schema["properties"] = required, properties
+	if len(required) > 0 { // required 不允许为空数组
+		schema["required"] = required
+	}
+	schema["properties"] = properties
 
 	return schema
 }
@@ -202,7 +227,7 @@ func (m *ModelReflect) Metadata() *Metadata { return m.metadata }
 // 提取结构体字段信息并添加到元信息中
 func (m *ModelReflect) extractField(structField reflect.StructField, no int) {
 	// 过滤模型基类
-	if structField.Anonymous && (structField.Name == "BaseModel" || structField.Name == "Field") {
+	if structField.Anonymous && (structField.Name == "BaseModel" || structField.Name == "Field" || structField.Name == "FileModel") {
 		return
 	}
 	// 过滤约定的匿名字段
@@ -218,6 +243,10 @@ func (m *ModelReflect) extractField(structField reflect.StructField, no int) {
 		m.metadata.AddInnerField(fieldMeta)
 	}
 
+	if fieldMeta.Format == BinaryFormat {
+		return // 上传文件,无需继续递归处理
+	}
+
 	switch fieldMeta.OType {
 	case IntegerType, NumberType, BoolType, StringType:
 		return // 基本类型,无需继续递归处理
@@ -260,6 +289,13 @@ func (m *ModelReflect) structFieldToMetaField(field reflect.StructField) *MetaFi
 		RType:     field.Type,
 	}
 
+	if isFileType(field.Type) { // 上传文件以二进制字符串描述
+		mf.Format = BinaryFormat
+		if field.Type == fileHeaderType {
+			mf.OType = StringType
+		}
+	}
+
 	if field.PkgPath == "" { // 对于结构体字段，此值无意义
 		mf._pkg = m.metadata.String() + "." + field.Name
 	}
@@ -2,18 +2,61 @@ package godantic
 
 import (
 	"github.com/Chendemo12/functools/helper"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 	"unicode"
 )
 
-// QModel 查询参数或路径参数模型, 此类型会进一步转换为 openapi.Parameter
+const ( // 查询参数转换错误信息
+	QueryValueNotInteger = "value is not a valid integer"
+	QueryValueNotNumber  = "value is not a valid number"
+	QueryValueNotBool    = "value could not be parsed to a boolean"
+	QueryValueNotTime    = "value is not a valid datetime"
+	QueryValueOverflow   = "value out of range"
+)
+
+const DateTimeFormat = "date-time"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// 时间类型参数支持的格式
+var timeLayouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+const ( // 参数位置
+	InQuery  = "query"
+	InPath   = "path"
+	InHeader = "header"
+	InCookie = "cookie"
+	InBody   = "body"
+)
+
+// QModel 查询参数,路径参数,请求头参数或cookie参数模型, 此类型会进一步转换为 openapi.Parameter
 type QModel struct {
+	RType  reflect.Type      `json:"-" description:"字段的Go类型, 为空时按字符串处理"`
 	Title  string            `json:"names,omitempty" description:"字段名称"`
 	Tag    reflect.StructTag `json:"tag,omitempty" description:"TAG"`
 	OType  OpenApiDataType   `json:"otype,omitempty" description:"openaapi 数据类型"`
+	In     string            `json:"in,omitempty" description:"参数位置, 缺省时由 InPath 决定"`
 	InPath bool              `json:"in_path,omitempty" description:"是否是路径参数"`
 }
 
+// NewQModel 从结构体字段创建一个查询参数模型, 参数类型取决于字段的Go类型
+//
+//	@param	field	reflect.StructField	结构体字段
+//	@param	inPath	bool				是否是路径参数
+func NewQModel(field reflect.StructField, inPath bool) *QModel {
+	return &QModel{
+		Title:  field.Name,
+		Tag:    field.Tag,
+		RType:  field.Type,
+		OType:  qmodelOType(field.Type),
+		InPath: inPath,
+	}
+}
+
 // Schema 输出为OpenAPI文档模型,字典格式
 //
 //	{
@@ -36,8 +79,86 @@ func (q *QModel) SchemaName(exclude ...bool) string { return QueryJsonName(q.Tag
 // SchemaDesc 结构体文档注释
 func (q *QModel) SchemaDesc() string { return QueryFieldTag(q.Tag, "description", q.Title) }
 
-// SchemaType 模型类型
-func (q *QModel) SchemaType() OpenApiDataType { return StringType }
+// SchemaType 模型类型, 由字段的Go类型决定, 缺省为字符串
+func (q *QModel) SchemaType() OpenApiDataType {
+	if q.OType == "" {
+		return StringType
+	}
+	return q.OType
+}
+
+// SchemaFormat 数据格式, 目前仅时间类型存在格式
+func (q *QModel) SchemaFormat() string {
+	if q.RType != nil && derefType(q.RType) == timeType {
+		return DateTimeFormat
+	}
+	return ""
+}
+
+// ItemType 数组元素类型, 仅 SchemaType 为 ArrayType 时有效
+func (q *QModel) ItemType() OpenApiDataType {
+	if q.RType == nil || q.SchemaType() != ArrayType {
+		return ""
+	}
+	return qmodelOType(derefType(q.RType).Elem())
+}
+
+// Location 参数位置，取值为 InQuery, InPath, InHeader 或 InCookie
+func (q *QModel) Location() string {
+	if q.In != "" {
+		return q.In
+	}
+	if q.InPath {
+		return InPath
+	}
+	return InQuery
+}
+
+// ParseValue 将查询参数或路径参数的原始值转换为字段定义的类型，并校验其取值范围
+// 对于数组类型, 每一个原始值均会转换为一个元素; 对于其他类型，仅第一个原始值有效
+//
+//	@param	values	[]string	参数原始值
+//	@return	any 转换后的参数值
+//	@return	*ValidationError 转换或校验错误
+func (q *QModel) ParseValue(values ...string) (any, *ValidationError) {
+	loc := []string{q.Location(), q.SchemaName()}
+	if len(values) == 0 {
+		return nil, newValidationError(loc, FieldRequired, q.SchemaType(), nil)
+	}
+	if q.RType == nil { // 未定义类型的参数按字符串处理
+		return values[0], nil
+	}
+
+	rt := derefType(q.RType)
+	var rv reflect.Value
+
+	if rt.Kind() == reflect.Slice && rt != timeType {
+		rv = reflect.MakeSlice(rt, 0, len(values))
+		for i := 0; i < len(values); i++ {
+			elem, ve := parseQueryValue(rt.Elem(), values[i], appendLoc(loc, strconv.Itoa(i)))
+			if ve != nil {
+				return nil, ve
+			}
+			if rt.Elem().Kind() == reflect.Pointer { // 数组元素为指针
+				ptr := reflect.New(elem.Type())
+				ptr.Elem().Set(elem)
+				elem = ptr
+			}
+			rv = reflect.Append(rv, elem)
+		}
+	} else {
+		elem, ve := parseQueryValue(rt, values[0], loc)
+		if ve != nil {
+			return nil, ve
+		}
+		rv = elem
+	}
+
+	if ve := validateConstraint(q.Tag, rv, loc); ve != nil {
+		return nil, ve
+	}
+	return rv.Interface(), nil
+}
 
 // SchemaJson 输出为OpenAPI文档模型,字符串格式
 func (q *QModel) SchemaJson() string {
@@ -58,27 +179,275 @@ func (q *QModel) InnerSchema() (m map[string]map[string]any) {
 // IsRequired 是否必须
 func (q *QModel) IsRequired() bool { return IsFieldRequired(q.Tag) }
 
-// QueryModel 查询参数基类
-type QueryModel struct{}
+// HeaderModel 请求头参数基类, 嵌入此基类的结构体的每一个导出字段都将作为一个请求头参数
+type HeaderModel struct {
+	QueryModel
+}
+
+// Location 参数位置, 恒为 InHeader
+func (q *HeaderModel) Location() string { return InHeader }
+
+// CookieModel cookie参数基类, 嵌入此基类的结构体的每一个导出字段都将作为一个cookie参数
+type CookieModel struct {
+	QueryModel
+}
+
+// Location 参数位置, 恒为 InCookie
+func (q *CookieModel) Location() string { return InCookie }
+
+// QueryModel 查询参数基类, 嵌入此基类的结构体的每一个导出字段都将作为一个查询参数
+type QueryModel struct {
+	_rt reflect.Type `description:"嵌入此基类的结构体类型"`
+}
 
+// Location 参数位置, 恒为 InQuery
+func (q *QueryModel) Location() string { return InQuery }
+
+// Fields 获取查询参数字段, 反射自嵌入此基类的结构体, 此结构体类型在路由注册时通过 SetType 设置
 func (q *QueryModel) Fields() []*QModel {
-	rt := reflect.TypeOf(q)
-	// 当此model作为查询参数时，此struct的每一个字段都将作为一个查询参数
-	m := make([]*QModel, rt.NumField())
+	if q._rt == nil {
+		return make([]*QModel, 0)
+	}
+	return StructQModels(q._rt)
+}
+
+// SetType 设置嵌入此基类的结构体类型
+func (q *QueryModel) SetType(rt reflect.Type) { q._rt = derefType(rt) }
+
+// ParameterLocation 参数模型的位置, 嵌入了 HeaderModel 或 CookieModel 的结构体分别为 InHeader 和 InCookie, 其他均为 InQuery
+//
+//	@param	model	QueryParameter	参数模型
+//	@return	string 参数位置
+func ParameterLocation(model QueryParameter) string {
+	if m, ok := model.(interface{ Location() string }); ok {
+		return m.Location()
+	}
+	return InQuery
+}
+
+// ReflectQueryModel 获取查询参数模型的全部字段, 对于嵌入了 QueryModel 的结构体会首先记录其结构体类型
+func ReflectQueryModel(model QueryParameter) []*QModel {
+	if m, ok := model.(interface{ SetType(rt reflect.Type) }); ok {
+		m.SetType(reflect.TypeOf(model))
+	}
+
+	fields := make([]*QModel, 0)
+	for _, qm := range model.Fields() {
+		if qm != nil {
+			fields = append(fields, qm)
+		}
+	}
+	return fields
+}
+
+// StructQModels 反射结构体的导出字段为查询参数, 嵌入结构体的字段与父结构体同级
+func StructQModels(rt reflect.Type) []*QModel {
+	rt = derefType(rt)
+	m := make([]*QModel, 0)
+	if rt.Kind() != reflect.Struct {
+		return m
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if unicode.IsLower(rune(field.Name[0])) || field.Name[0] == '_' {
+			continue
+		}
+		if field.Anonymous {
+			if !isParameterBase(field.Type) && derefType(field.Type).Kind() == reflect.Struct {
+				m = append(m, StructQModels(field.Type)...)
+			}
+			continue
+		}
+		if QueryJsonName(field.Tag, field.Name) == "-" {
+			continue
+		}
+		// 仅导出字段可用
+		m = append(m, NewQModel(field, false))
+	}
+	return m
+}
+
+// isParameterBase 是否是参数模型的基类 QueryModel, HeaderModel 或 CookieModel
+func isParameterBase(rt reflect.Type) bool {
+	switch rt {
+	case reflect.TypeOf(QueryModel{}), reflect.TypeOf(HeaderModel{}), reflect.TypeOf(CookieModel{}):
+		return true
+	}
+	return false
+}
+
+// BindQuery 将查询参数绑定到结构体, 结构体的每一个导出字段均作为一个查询参数(以json标签为准)
+// 参数值依据字段类型进行转换和范围校验，对于缺省的参数，若定义了 default 标签则以默认值填充
+//
+//	@param	stc		any							结构体指针
+//	@param	lookup	func(name string) []string	查询参数取值方法，返回参数的全部原始值
+//	@return	*ValidationError 绑定或校验错误
+func BindQuery(stc any, lookup func(name string) []string) *ValidationError {
+	rv := reflect.ValueOf(stc)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return newValidationError([]string{InQuery}, ValueTypeMismatch, ObjectType, map[string]any{"received": rv.Type().String()})
+	}
+
+	return bindValuesStruct(rv.Elem(), InQuery, lookup)
+}
+
+// BindForm 将 application/x-www-form-urlencoded 表单绑定到结构体, 结构体的每一个导出字段均作为一个表单字段(以json标签为准)
+// 字段值依据字段类型进行转换和范围校验，对于缺省的字段，若定义了 default 标签则以默认值填充
+//
+//	@param	stc		any							结构体指针
+//	@param	lookup	func(name string) []string	表单字段取值方法，返回字段的全部原始值
+//	@return	*ValidationError 绑定或校验错误
+func BindForm(stc any, lookup func(name string) []string) *ValidationError {
+	rv := reflect.ValueOf(stc)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return newValidationError([]string{InBody}, ValueTypeMismatch, ObjectType, map[string]any{"received": rv.Type().String()})
+	}
+
+	return bindValuesStruct(rv.Elem(), InBody, lookup)
+}
+
+// bindValuesStruct 将字符串形式的参数绑定到结构体
+//
+//	@param	in	string	参数位置, 用于错误定位
+func bindValuesStruct(rv reflect.Value, in string, lookup func(name string) []string) *ValidationError {
+	rt := rv.Type()
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
+		if !unicode.IsUpper(rune(field.Name[0])) {
+			continue
+		}
+		if field.Anonymous { // 嵌入结构体，其字段同样作为参数
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(QueryModel{}) && field.Name != "BaseModel" {
+				if ve := bindValuesStruct(rv.Field(i), in, lookup); ve != nil {
+					return ve
+				}
+			}
+			continue
+		}
 
-		if unicode.IsLower(rune(field.Name[0])) {
+		qm := NewQModel(field, false)
+		qm.In = in
+		name := qm.SchemaName()
+		if name == "-" {
 			continue
 		}
 
-		// 仅导出字段可用
-		m[i] = &QModel{
-			Title:  field.Name,
-			InPath: false,
-			Tag:    field.Tag,
-			OType:  ObjectType, // 无意义
+		values := lookup(name)
+		if len(values) == 0 {
+			if qm.IsRequired() {
+				return newValidationError([]string{in, name}, FieldRequired, qm.SchemaType(), nil)
+			}
+			dv := QueryFieldTag(field.Tag, "default", "")
+			if dv == "" {
+				continue
+			}
+			values = append(values, dv)
+		}
+
+		v, ve := qm.ParseValue(values...)
+		if ve != nil {
+			return ve
 		}
+		setFieldValue(rv.Field(i), reflect.ValueOf(v))
 	}
-	return m
+
+	return nil
+}
+
+// setFieldValue 为结构体字段赋值, 字段允许为指针类型
+func setFieldValue(fv reflect.Value, v reflect.Value) {
+	if fv.Kind() == reflect.Pointer {
+		ptr := reflect.New(fv.Type().Elem())
+		setFieldValue(ptr.Elem(), v)
+		fv.Set(ptr)
+		return
+	}
+	if v.Type().AssignableTo(fv.Type()) {
+		fv.Set(v)
+	} else if v.Type().ConvertibleTo(fv.Type()) {
+		fv.Set(v.Convert(fv.Type()))
+	}
+}
+
+// qmodelOType 查询参数的 openapi 数据类型, 时间类型以字符串表示
+func qmodelOType(rt reflect.Type) OpenApiDataType {
+	rt = derefType(rt)
+	if rt == timeType {
+		return StringType
+	}
+	return reflectKindToOType(rt.Kind())
+}
+
+func derefType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+// parseQueryValue 将字符串转换为指定类型的值，返回值的类型恒为 rt
+func parseQueryValue(rt reflect.Type, raw string, loc []string) (reflect.Value, *ValidationError) {
+	rt = derefType(rt)
+	rv := reflect.New(rt).Elem()
+	raw = strings.TrimSpace(raw)
+
+	if rt == timeType {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				rv.Set(reflect.ValueOf(t))
+				return rv, nil
+			}
+		}
+		return rv, newValidationError(loc, QueryValueNotTime, StringType, map[string]any{"input": raw})
+	}
+
+	switch rt.Kind() {
+	case reflect.String:
+		rv.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return rv, newValidationError(loc, QueryValueNotBool, BoolType, map[string]any{"input": raw})
+		}
+		rv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return rv, newValidationError(loc, QueryValueNotInteger, IntegerType, map[string]any{"input": raw})
+		}
+		if rv.OverflowInt(i) {
+			return rv, newValidationError(loc, QueryValueOverflow, IntegerType, map[string]any{"input": raw})
+		}
+		rv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return rv, newValidationError(loc, QueryValueNotInteger, IntegerType, map[string]any{"input": raw})
+		}
+		if rv.OverflowUint(u) {
+			return rv, newValidationError(loc, QueryValueOverflow, IntegerType, map[string]any{"input": raw})
+		}
+		rv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return rv, newValidationError(loc, QueryValueNotNumber, NumberType, map[string]any{"input": raw})
+		}
+		if rv.OverflowFloat(f) {
+			return rv, newValidationError(loc, QueryValueOverflow, NumberType, map[string]any{"input": raw})
+		}
+		rv.SetFloat(f)
+
+	default: // 不支持的类型按字符串处理, 若类型不兼容则保持零值
+		if rt.Kind() == reflect.Interface {
+			rv.Set(reflect.ValueOf(raw))
+		}
+	}
+
+	return rv, nil
 }
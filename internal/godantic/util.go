@@ -51,19 +51,25 @@ func GetDefaultV(tag reflect.StructTag, otype OpenApiDataType) (v any) {
 	if defaultV == "" {
 		v = nil
 	} else { // 存在默认值
-		switch otype {
+		v = StringToOType(defaultV, otype)
+	}
+	return
+}
 
-		case StringType:
-			v = defaultV
-		case IntegerType:
-			v, _ = strconv.Atoi(defaultV)
-		case NumberType:
-			v, _ = strconv.ParseFloat(defaultV, 64)
-		case BoolType:
-			v, _ = strconv.ParseBool(defaultV)
-		default:
-			v = defaultV
-		}
+// StringToOType 将字符串转换为 openapi 数据类型对应的值, 如默认值和枚举值
+func StringToOType(s string, otype OpenApiDataType) (v any) {
+	switch otype {
+
+	case StringType:
+		v = s
+	case IntegerType:
+		v, _ = strconv.Atoi(s)
+	case NumberType:
+		v, _ = strconv.ParseFloat(s, 64)
+	case BoolType:
+		v, _ = strconv.ParseBool(s)
+	default:
+		v = s
 	}
 	return
 }
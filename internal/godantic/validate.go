@@ -0,0 +1,332 @@
+package godantic
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const ( // 校验错误信息
+	FieldRequired     = "field required"
+	ValueTypeMismatch = "value type mismatch"
+	ValueNotInEnum    = "value is not a valid enumeration member"
+	ValueLessThan     = "ensure this value is greater than or equal to the limit"
+	ValueGreaterThan  = "ensure this value is less than or equal to the limit"
+	ModelNotEmbedded  = "value is not a model embedding BaseModel"
+	StringNotMatch    = "string does not match the pattern"
+	ConstraintFailed  = "value does not satisfy the constraint"
+)
+
+var fieldType = reflect.TypeOf(Field{})
+
+// tagValidator 校验 validate 标签, 字段名称以 json 标签为准, 以便与 ValidateModel 的错误定位保持一致
+var tagValidator = newTagValidator()
+
+func newTagValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return QueryJsonName(field.Tag, field.Name)
+	})
+	return v
+}
+
+// ValidateModel 依据模型定义校验一个实例值，支持基本数据类型 Field，数组类型 List 和 BaseModel 结构体
+// 仅校验 required, oneof, gte, lte 和 pattern 标签，对于 BaseModel 会递归校验其内部结构体字段，
+// 结构体中为零值的可选字段不校验 oneof, gte, lte 和 pattern 约束
+//
+//	@param	model	SchemaIface	模型定义
+//	@param	v		any			待校验的实例值，允许为指针
+//	@param	loc		[]string	错误定位的前缀，如 "response"
+//	@return	[]*ValidationError 校验错误，校验通过则为空
+func ValidateModel(model SchemaIface, v any, loc ...string) []*ValidationError {
+	if model == nil {
+		return nil
+	}
+	rv := indirectValue(reflect.ValueOf(v))
+
+	switch m := model.(type) {
+	case *Field: // 基本数据类型
+		if !rv.IsValid() {
+			return []*ValidationError{newValidationError(loc, FieldRequired, m.OType, nil)}
+		}
+		return validateField(m.Tag, m.OType, rv, loc)
+
+	case *MetaField: // List
+		if !rv.IsValid() {
+			return []*ValidationError{newValidationError(loc, FieldRequired, ArrayType, nil)}
+		}
+		return validateList(m, rv, loc)
+
+	default: // BaseModel
+		meta, err := model.Metadata()
+		if err != nil {
+			return nil
+		}
+		if !rv.IsValid() {
+			return []*ValidationError{newValidationError(loc, FieldRequired, ObjectType, nil)}
+		}
+		if rv.Kind() != reflect.Struct || rv.Type().String() != meta.String() {
+			return []*ValidationError{newValidationError(
+				loc, ValueTypeMismatch, ObjectType, map[string]any{"expected": meta.String(), "received": rv.Type().String()},
+			)}
+		}
+		return validateStruct(rv, loc)
+	}
+}
+
+// validateList 校验数组的每一个元素
+func validateList(m *MetaField, rv reflect.Value, loc []string) []*ValidationError {
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []*ValidationError{newValidationError(
+			loc, ValueTypeMismatch, ArrayType, map[string]any{"received": rv.Type().String()},
+		)}
+	}
+
+	ves := make([]*ValidationError, 0)
+	for i := 0; i < rv.Len(); i++ {
+		elem := indirectValue(rv.Index(i))
+		elemLoc := appendLoc(loc, strconv.Itoa(i))
+
+		if m.RType == fieldType { // 数组元素为基本数据类型
+			otype := StringType
+			if meta := GetMetadata(m.ItemRef); meta != nil {
+				otype = meta.SchemaType()
+			}
+			if !elem.IsValid() {
+				ves = append(ves, newValidationError(elemLoc, FieldRequired, otype, nil))
+				continue
+			}
+			ves = append(ves, validateField(m.Tag, otype, elem, elemLoc)...)
+			continue
+		}
+
+		// 数组元素为结构体
+		if !elem.IsValid() {
+			ves = append(ves, newValidationError(elemLoc, FieldRequired, ObjectType, nil))
+			continue
+		}
+		if elem.Kind() != reflect.Struct || elem.Type().String() != m.ItemRef {
+			ves = append(ves, newValidationError(
+				elemLoc, ValueTypeMismatch, ObjectType, map[string]any{"expected": m.ItemRef, "received": elem.Type().String()},
+			))
+			continue
+		}
+		ves = append(ves, validateStruct(elem, elemLoc)...)
+	}
+
+	return ves
+}
+
+// validateField 校验基本数据类型
+func validateField(tag reflect.StructTag, otype OpenApiDataType, rv reflect.Value, loc []string) []*ValidationError {
+	kind := reflectKindToOType(rv.Kind())
+	// 整数同样是合法的数字类型
+	if kind != otype && !(otype == NumberType && kind == IntegerType) {
+		return []*ValidationError{newValidationError(
+			loc, ValueTypeMismatch, otype, map[string]any{"received": rv.Type().String()},
+		)}
+	}
+
+	if ve := validateConstraint(tag, rv, loc); ve != nil {
+		return []*ValidationError{ve}
+	}
+	return nil
+}
+
+// validateStruct 校验结构体的全部导出字段
+func validateStruct(rv reflect.Value, loc []string) []*ValidationError {
+	ves := make([]*ValidationError, 0)
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		// 过滤模型基类和非导出字段
+		if field.Anonymous && (field.Name == "BaseModel" || field.Name == "Field" || field.Name == "FileModel") {
+			continue
+		}
+		if !unicode.IsUpper(rune(field.Name[0])) {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if field.Anonymous { // 嵌入结构体，其字段与父结构体同级
+			if fv = indirectValue(fv); fv.IsValid() && fv.Kind() == reflect.Struct {
+				ves = append(ves, validateStruct(fv, loc)...)
+			}
+			continue
+		}
+
+		name := QueryJsonName(field.Tag, field.Name)
+		if name == "-" {
+			continue
+		}
+		ves = append(ves, validateValue(field.Tag, fv, appendLoc(loc, name))...)
+	}
+
+	return ves
+}
+
+// validateValue 校验结构体字段
+func validateValue(tag reflect.StructTag, fv reflect.Value, loc []string) []*ValidationError {
+	required, zero := IsFieldRequired(tag), fv.IsZero()
+	if required && zero {
+		return []*ValidationError{newValidationError(loc, FieldRequired, reflectKindToOType(fv.Kind()), nil)}
+	}
+
+	fv = indirectValue(fv)
+	if !fv.IsValid() { // 可选字段为空
+		return nil
+	}
+
+	// 可选字段为零值时视为未赋值, 不校验其约束, 否则未设置的枚举字段或长度受限的字段将无法通过校验
+	if required || !zero {
+		if ve := validateConstraint(tag, fv, loc); ve != nil {
+			return []*ValidationError{ve}
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		return validateStruct(fv, loc)
+
+	case reflect.Slice, reflect.Array:
+		ves := make([]*ValidationError, 0)
+		for i := 0; i < fv.Len(); i++ {
+			elem := indirectValue(fv.Index(i))
+			if elem.IsValid() && elem.Kind() == reflect.Struct {
+				ves = append(ves, validateStruct(elem, appendLoc(loc, strconv.Itoa(i)))...)
+			}
+		}
+		return ves
+
+	default:
+		return nil
+	}
+}
+
+// validateConstraint 校验 oneof, gte, lte 和 pattern 标签
+// 对于数字类型 gte/lte 约束其取值范围，对于字符串和数组类型则约束其长度
+func validateConstraint(tag reflect.StructTag, rv reflect.Value, loc []string) *ValidationError {
+	otype := reflectKindToOType(rv.Kind())
+
+	if es := QueryFieldTag(tag, "oneof", ""); es != "" && (otype != ObjectType && otype != ArrayType) {
+		value := fmt.Sprint(rv.Interface())
+		found := false
+		for _, e := range strings.Fields(es) {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return newValidationError(loc, ValueNotInEnum, otype, map[string]any{"enum_values": strings.Fields(es)})
+		}
+	}
+
+	if gte := QueryFieldTag(tag, "gte", ""); gte != "" {
+		if cmp, ok := compareLimit(rv, gte); ok && cmp < 0 {
+			return newValidationError(loc, ValueLessThan, otype, map[string]any{"limit_value": gte})
+		}
+	}
+	if lte := QueryFieldTag(tag, "lte", ""); lte != "" {
+		if cmp, ok := compareLimit(rv, lte); ok && cmp > 0 {
+			return newValidationError(loc, ValueGreaterThan, otype, map[string]any{"limit_value": lte})
+		}
+	}
+
+	if pattern := QueryFieldTag(tag, "pattern", ""); pattern != "" && rv.Kind() == reflect.String {
+		if matched, err := regexp.MatchString(pattern, rv.String()); err == nil && !matched {
+			return newValidationError(loc, StringNotMatch, otype, map[string]any{"pattern": pattern})
+		}
+	}
+
+	return nil
+}
+
+// ValidateTags 以 validator 校验结构体的 validate 标签, 如: min, max 和 email, 与 Service.Validate 的校验规则一致
+//
+//	@param	stc	any			结构体或结构体指针
+//	@param	loc	[]string	错误定位的前缀, 如 "body"
+//	@return	[]*ValidationError 校验错误，校验通过则为空
+func ValidateTags(stc any, loc ...string) []*ValidationError {
+	err := tagValidator.Struct(stc)
+	if err == nil {
+		return nil
+	}
+	fes, ok := err.(validator.ValidationErrors)
+	if !ok { // 非结构体等无效参数
+		return []*ValidationError{newValidationError(loc, ModelNotEmbedded, ObjectType, nil)}
+	}
+
+	ves := make([]*ValidationError, len(fes))
+	for i, fe := range fes {
+		// 命名空间形如 User.tags[0].name, 去除首个结构体名称后转换为错误定位
+		_, ns, _ := strings.Cut(fe.Namespace(), ".")
+		fieldLoc := loc
+		for _, name := range strings.FieldsFunc(ns, func(r rune) bool { return r == '.' || r == '[' || r == ']' }) {
+			fieldLoc = appendLoc(fieldLoc, name)
+		}
+
+		msg := ConstraintFailed
+		if fe.Tag() == "required" {
+			msg = FieldRequired
+		}
+		ves[i] = newValidationError(fieldLoc, msg, reflectKindToOType(fe.Kind()), map[string]any{
+			"tag": fe.Tag(), "param": fe.Param(),
+		})
+	}
+	return ves
+}
+
+// compareLimit 比较实例值与约束值的大小, 返回值与 big.Float.Cmp 一致
+func compareLimit(rv reflect.Value, limit string) (int, bool) {
+	bound, ok := new(big.Float).SetString(strings.TrimSpace(limit))
+	if !ok {
+		return 0, false
+	}
+
+	value := new(big.Float)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value.SetUint64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat64(rv.Float())
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		value.SetInt64(int64(rv.Len()))
+	default:
+		return 0, false
+	}
+
+	return value.Cmp(bound), true
+}
+
+// indirectValue 解引用指针和接口, 若为空指针则返回无效值
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func appendLoc(loc []string, name string) []string {
+	l := make([]string, len(loc), len(loc)+1)
+	copy(l, loc)
+	return append(l, name)
+}
+
+func newValidationError(loc []string, msg string, otype OpenApiDataType, ctx map[string]any) *ValidationError {
+	if ctx == nil {
+		ctx = map[string]any{}
+	}
+	return &ValidationError{Loc: loc, Msg: msg, Type: string(otype), Ctx: ctx}
+}
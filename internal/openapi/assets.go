@@ -0,0 +1,103 @@
+package openapi
+
+import (
+	"embed"
+	"strings"
+)
+
+// 文档页面所需的静态文件, 以便在无法访问外网的环境中使用文档页面:
+// assets/swagger-ui 来自 swagger-ui-dist@4.15.5, assets/redoc 来自 redoc@2.0.0;
+// 若 assets/redoc 中不存在 redoc.standalone.js, 则 ReDoc 仍通过 RedocJsUrl 加载
+//
+//go:generate curl -fsSL -o assets/redoc/redoc.standalone.js https://cdn.jsdelivr.net/npm/redoc@2.0.0/bundles/redoc.standalone.js
+//go:embed assets
+var assets embed.FS
+
+// 本地静态文件所在的目录, 按顺序查找
+var assetDirs = []string{"assets/swagger-ui/", "assets/redoc/"}
+
+const redocJsFile = "redoc.standalone.js"
+
+const (
+	DocsUrl    = "/docs"         // swagger 在线调试页面路由
+	RedocUrl   = "/redoc"        // redoc 文档页面路由
+	OpenapiUrl = "/openapi.json" // openapi 文档路由
+	StaticUrl  = "/docs/static"  // 本地静态文件路由前缀
+)
+
+// DocsConfig 文档页面的路由及其所需静态文件的地址
+type DocsConfig struct {
+	DocsUrl           string // swagger 在线调试页面路由
+	RedocUrl          string // redoc 文档页面路由
+	OpenapiUrl        string // openapi 文档路由
+	StaticUrl         string // 本地静态文件路由前缀, 为空则不挂载本地静态文件
+	SwaggerJsUrl      string // swagger-ui-bundle.js 地址
+	SwaggerCssUrl     string // swagger-ui.css 地址
+	SwaggerFaviconUrl string // swagger 页面图标地址
+	RedocJsUrl        string // redoc.standalone.js 地址
+	RedocFaviconUrl   string // redoc 页面图标地址
+	RedocFontsUrl     string // redoc 页面字体样式地址, 为空则使用浏览器缺省字体
+	Version           string // openapi 文档版本, Version303 或 Version310
+}
+
+// NewDocsConfig 创建默认的文档页面配置, 文档页面的静态文件由本地提供
+func NewDocsConfig() *DocsConfig {
+	c := &DocsConfig{
+		DocsUrl:    DocsUrl,
+		RedocUrl:   RedocUrl,
+		OpenapiUrl: OpenapiUrl,
+		Version:    ApiVersion,
+	}
+	return c.UseLocalAssets()
+}
+
+// UseLocalAssets 使用本地静态文件, 未嵌入 redoc.standalone.js 时 ReDoc 仍通过CDN加载; redoc 页面不再加载 Google Fonts
+func (c *DocsConfig) UseLocalAssets() *DocsConfig {
+	c.StaticUrl = StaticUrl
+	c.SwaggerJsUrl = StaticUrl + "/swagger-ui-bundle.js"
+	c.SwaggerCssUrl = StaticUrl + "/swagger-ui.css"
+	c.SwaggerFaviconUrl = StaticUrl + "/favicon-32x32.png"
+	c.RedocFaviconUrl = StaticUrl + "/favicon-32x32.png"
+	c.RedocJsUrl = RedocJsUrl
+	c.RedocFontsUrl = ""
+	if HasStaticFile(redocJsFile) {
+		c.RedocJsUrl = StaticUrl + "/" + redocJsFile
+	}
+	return c
+}
+
+// UseCDNAssets 使用CDN上的静态文件, 不再挂载本地静态文件
+func (c *DocsConfig) UseCDNAssets() *DocsConfig {
+	c.StaticUrl = ""
+	c.SwaggerJsUrl = SwaggerJsUrl
+	c.SwaggerCssUrl = SwaggerCssUrl
+	c.SwaggerFaviconUrl = SwaggerFaviconUrl
+	c.RedocJsUrl = RedocJsUrl
+	c.RedocFaviconUrl = RedocFaviconUrl
+	c.RedocFontsUrl = RedocFontsUrl
+	return c
+}
+
+// Oauth2RedirectUrl swagger OAuth2 认证的回调路由
+func (c *DocsConfig) Oauth2RedirectUrl() string {
+	return strings.TrimSuffix(c.DocsUrl, PathSeparator) + "/oauth2-redirect"
+}
+
+// StaticFile 读取本地静态文件
+//
+//	@param	name	string	文件名, 如: swagger-ui.css
+//	@return	[]byte 文件内容
+func StaticFile(name string) (bs []byte, err error) {
+	for _, dir := range assetDirs {
+		if bs, err = assets.ReadFile(dir + name); err == nil {
+			return bs, nil
+		}
+	}
+	return nil, err
+}
+
+// HasStaticFile 是否存在本地静态文件
+func HasStaticFile(name string) bool {
+	_, err := StaticFile(name)
+	return err == nil
+}
@@ -0,0 +1,606 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+// ChangeLevel 文档变更的兼容性级别
+type ChangeLevel string
+
+const (
+	ChangeBreaking    ChangeLevel = "breaking"     // 不兼容的变更, 已有的客户端可能无法正常工作
+	ChangeNonBreaking ChangeLevel = "non-breaking" // 兼容的变更
+)
+
+// 文档变更的类型
+const (
+	PathRemoved                = "path-removed"
+	PathAdded                  = "path-added"
+	OperationRemoved           = "operation-removed"
+	OperationAdded             = "operation-added"
+	ParameterRemoved           = "parameter-removed"
+	ParameterAdded             = "parameter-added"
+	ParameterBecameRequired    = "parameter-became-required"
+	ParameterBecameOptional    = "parameter-became-optional"
+	RequestBodyAdded           = "request-body-added"
+	RequestBodyRemoved         = "request-body-removed"
+	RequestBodyBecameRequired  = "request-body-became-required"
+	RequestMediaTypeRemoved    = "request-media-type-removed"
+	RequestMediaTypeAdded      = "request-media-type-added"
+	RequestPropertyRemoved     = "request-property-removed"
+	RequestPropertyAdded       = "request-property-added"
+	RequestPropertyRequired    = "request-property-became-required"
+	RequestPropertyOptional    = "request-property-became-optional"
+	ResponseRemoved            = "response-removed"
+	ResponseAdded              = "response-added"
+	ResponseMediaTypeRemoved   = "response-media-type-removed"
+	ResponseMediaTypeAdded     = "response-media-type-added"
+	ResponsePropertyRemoved    = "response-property-removed"
+	ResponsePropertyAdded      = "response-property-added"
+	ResponsePropertyOptional   = "response-property-became-optional"
+	ResponsePropertyRequired   = "response-property-became-required"
+	TypeChanged                = "type-changed"
+	EnumNarrowed               = "enum-narrowed"
+	EnumWidened                = "enum-widened"
+	SecurityRequirementAdded   = "security-requirement-added"
+	SecurityRequirementRemoved = "security-requirement-removed"
+	OperationBecameDeprecated  = "operation-deprecated"
+)
+
+// Change 一项文档变更
+type Change struct {
+	Level   ChangeLevel `json:"level" description:"兼容性级别"`
+	Kind    string      `json:"kind" description:"变更类型, 如: path-removed"`
+	Path    string      `json:"path,omitempty" description:"路由"`
+	Method  string      `json:"method,omitempty" description:"请求方法"`
+	Pointer string      `json:"pointer" description:"变更位置, 形如 json pointer, 新文档中不存在时为旧文档中的位置, 参数以 in:name 代替数组下标"`
+	Message string      `json:"message" description:"变更说明"`
+}
+
+// DiffReport 两个文档的比较结果, 可直接序列化为 json 供 CI 使用
+type DiffReport struct {
+	Breaking    bool      `json:"breaking" description:"是否存在不兼容的变更"`
+	BreakingNum int       `json:"breaking_num" description:"不兼容的变更数量"`
+	Changes     []*Change `json:"changes" description:"全部变更, 按位置排序"`
+}
+
+// BreakingChanges 不兼容的变更
+func (r *DiffReport) BreakingChanges() []*Change {
+	changes := make([]*Change, 0, r.BreakingNum)
+	for _, c := range r.Changes {
+		if c.Level == ChangeBreaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// Diff 比较两个版本的文档, 并区分兼容和不兼容的变更, 文档可以是 json 或 yaml 格式, 版本可以是 3.0 或 3.1;
+// 不兼容的变更包括: 移除路由或方法, 新增必需的参数或请求体字段, 参数或字段的类型改变, 请求中的枚举值减少,
+// 响应中的枚举值增加, 移除响应状态码、响应字段或数据格式, 以及新增认证要求
+//
+//	@param	older	[]byte	旧版本文档
+//	@param	newer	[]byte	新版本文档
+//	@return	*DiffReport 比较结果
+func Diff(older, newer []byte) (*DiffReport, error) {
+	oldDoc, err := loadDocument(older)
+	if err != nil {
+		return nil, fmt.Errorf("old document: %w", err)
+	}
+	newDoc, err := loadDocument(newer)
+	if err != nil {
+		return nil, fmt.Errorf("new document: %w", err)
+	}
+
+	d := &differ{older: oldDoc, newer: newDoc, changes: make([]*Change, 0)}
+	d.diffPaths()
+
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].Pointer < d.changes[j].Pointer })
+	report := &DiffReport{Changes: d.changes}
+	for _, c := range d.changes {
+		if c.Level == ChangeBreaking {
+			report.BreakingNum++
+		}
+	}
+	report.Breaking = report.BreakingNum > 0
+
+	return report, nil
+}
+
+// Diff 比较当前文档与新版本的文档
+func (o *OpenApi) Diff(newer *OpenApi) (*DiffReport, error) {
+	older, err := o.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	bs, err := newer.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return Diff(older, bs)
+}
+
+// loadDocument 解析文档, json 是 yaml 的子集, 因此统一按 yaml 解析, 使两种格式的数字类型保持一致
+func loadDocument(doc []byte) (map[string]any, error) {
+	var root any
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	m, ok := normalizeYaml(root).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("document must be an object")
+	}
+	return m, nil
+}
+
+// normalizeYaml 将 yaml 中非字符串的键(如未加引号的状态码 200)转换为字符串
+func normalizeYaml(node any) any {
+	switch n := node.(type) {
+	case map[string]any:
+		for k, v := range n {
+			n[k] = normalizeYaml(v)
+		}
+		return n
+	case map[any]any:
+		m := make(map[string]any, len(n))
+		for k, v := range n {
+			m[fmt.Sprint(k)] = normalizeYaml(v)
+		}
+		return m
+	case []any:
+		for i, v := range n {
+			n[i] = normalizeYaml(v)
+		}
+		return n
+	}
+	return node
+}
+
+type differ struct {
+	older   map[string]any
+	newer   map[string]any
+	changes []*Change
+}
+
+// 当前正在比较的操作
+type diffScope struct {
+	path    string
+	method  string
+	request bool // 是否为请求, 决定类型和枚举变更的兼容性方向
+}
+
+func (d *differ) add(level ChangeLevel, kind string, scope diffScope, pointer, format string, args ...any) {
+	d.changes = append(d.changes, &Change{
+		Level:   level,
+		Kind:    kind,
+		Path:    scope.path,
+		Method:  strings.ToUpper(scope.method),
+		Pointer: pointer,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) diffPaths() {
+	oldPaths, _ := d.older["paths"].(map[string]any)
+	newPaths, _ := d.newer["paths"].(map[string]any)
+
+	for _, path := range sortedKeys(oldPaths) {
+		pointer := "#/paths/" + godantic.EscapeJsonPointer(path)
+		scope := diffScope{path: path}
+		newItem, found := newPaths[path].(map[string]any)
+		if !found {
+			d.add(ChangeBreaking, PathRemoved, scope, pointer, "path '%s' was removed", path)
+			continue
+		}
+		oldItem, _ := oldPaths[path].(map[string]any)
+
+		for _, method := range httpMethods {
+			oldOp, hasOld := oldItem[method].(map[string]any)
+			newOp, hasNew := newItem[method].(map[string]any)
+			scope.method = method
+			switch {
+			case hasOld && !hasNew:
+				d.add(ChangeBreaking, OperationRemoved, scope, pointer+"/"+method, "operation %s %s was removed", strings.ToUpper(method), path)
+			case !hasOld && hasNew:
+				d.add(ChangeNonBreaking, OperationAdded, scope, pointer+"/"+method, "operation %s %s was added", strings.ToUpper(method), path)
+			case hasOld && hasNew:
+				d.diffOperation(scope, pointer+"/"+method, oldOp, newOp, oldItem["parameters"], newItem["parameters"])
+			}
+		}
+	}
+
+	for _, path := range sortedKeys(newPaths) {
+		if _, found := oldPaths[path]; !found {
+			d.add(ChangeNonBreaking, PathAdded, diffScope{path: path}, "#/paths/"+godantic.EscapeJsonPointer(path), "path '%s' was added", path)
+		}
+	}
+}
+
+func (d *differ) diffOperation(scope diffScope, pointer string, oldOp, newOp map[string]any, oldCommon, newCommon any) {
+	if oldDeprecated, _ := oldOp["deprecated"].(bool); !oldDeprecated {
+		if newDeprecated, _ := newOp["deprecated"].(bool); newDeprecated {
+			d.add(ChangeNonBreaking, OperationBecameDeprecated, scope, pointer+"/deprecated", "operation was deprecated")
+		}
+	}
+
+	scope.request = true
+	d.diffParameters(scope, pointer+"/parameters",
+		d.parameters(d.older, oldCommon, oldOp["parameters"]), d.parameters(d.newer, newCommon, newOp["parameters"]))
+	d.diffRequestBody(scope, pointer+"/requestBody", oldOp["requestBody"], newOp["requestBody"])
+	d.diffSecurity(scope, pointer+"/security", oldOp["security"], newOp["security"])
+
+	scope.request = false
+	oldResponses, _ := oldOp["responses"].(map[string]any)
+	newResponses, _ := newOp["responses"].(map[string]any)
+	for _, code := range sortedKeys(oldResponses) {
+		respPointer := pointer + "/responses/" + code
+		newResp, found := newResponses[code]
+		if !found {
+			d.add(ChangeBreaking, ResponseRemoved, scope, respPointer, "response '%s' was removed", code)
+			continue
+		}
+		oldContent, _ := d.resolve(d.older, oldResponses[code])["content"].(map[string]any)
+		newContent, _ := d.resolve(d.newer, newResp)["content"].(map[string]any)
+		d.diffContent(scope, respPointer+"/content", oldContent, newContent)
+	}
+	for _, code := range sortedKeys(newResponses) {
+		if _, found := oldResponses[code]; !found {
+			d.add(ChangeNonBreaking, ResponseAdded, scope, pointer+"/responses/"+code, "response '%s' was added", code)
+		}
+	}
+}
+
+// parameters 合并路由和操作的参数, 以 in:name 为键
+func (d *differ) parameters(doc map[string]any, lists ...any) map[string]map[string]any {
+	params := make(map[string]map[string]any)
+	for _, list := range lists {
+		items, _ := list.([]any)
+		for _, item := range items {
+			p := d.resolve(doc, item)
+			name, _ := p["name"].(string)
+			in, _ := p["in"].(string)
+			params[in+":"+name] = p
+		}
+	}
+	return params
+}
+
+func (d *differ) diffParameters(scope diffScope, pointer string, oldParams, newParams map[string]map[string]any) {
+	keys := make([]string, 0, len(oldParams))
+	for key := range oldParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		in, name, _ := strings.Cut(key, ":")
+		paramPointer := pointer + "/" + godantic.EscapeJsonPointer(key)
+		oldParam := oldParams[key]
+		newParam, found := newParams[key]
+		if !found {
+			d.add(ChangeNonBreaking, ParameterRemoved, scope, paramPointer, "%s parameter '%s' was removed", in, name)
+			continue
+		}
+
+		oldRequired, _ := oldParam["required"].(bool)
+		newRequired, _ := newParam["required"].(bool)
+		if !oldRequired && newRequired {
+			d.add(ChangeBreaking, ParameterBecameRequired, scope, paramPointer+"/required", "%s parameter '%s' became required", in, name)
+		} else if oldRequired && !newRequired {
+			d.add(ChangeNonBreaking, ParameterBecameOptional, scope, paramPointer+"/required", "%s parameter '%s' became optional", in, name)
+		}
+		d.diffSchema(scope, paramPointer+"/schema", oldParam["schema"], newParam["schema"], make(map[string]bool))
+	}
+
+	keys = keys[:0]
+	for key := range newParams {
+		if _, found := oldParams[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		in, name, _ := strings.Cut(key, ":")
+		if required, _ := newParams[key]["required"].(bool); required {
+			d.add(ChangeBreaking, ParameterAdded, scope, pointer+"/"+godantic.EscapeJsonPointer(key), "required %s parameter '%s' was added", in, name)
+		} else {
+			d.add(ChangeNonBreaking, ParameterAdded, scope, pointer+"/"+godantic.EscapeJsonPointer(key), "optional %s parameter '%s' was added", in, name)
+		}
+	}
+}
+
+func (d *differ) diffRequestBody(scope diffScope, pointer string, oldValue, newValue any) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		if required, _ := d.resolve(d.newer, newValue)["required"].(bool); required {
+			d.add(ChangeBreaking, RequestBodyAdded, scope, pointer, "required request body was added")
+		} else {
+			d.add(ChangeNonBreaking, RequestBodyAdded, scope, pointer, "optional request body was added")
+		}
+		return
+	case newValue == nil:
+		d.add(ChangeNonBreaking, RequestBodyRemoved, scope, pointer, "request body was removed")
+		return
+	}
+
+	oldBody, newBody := d.resolve(d.older, oldValue), d.resolve(d.newer, newValue)
+	oldRequired, _ := oldBody["required"].(bool)
+	newRequired, _ := newBody["required"].(bool)
+	if !oldRequired && newRequired {
+		d.add(ChangeBreaking, RequestBodyBecameRequired, scope, pointer+"/required", "request body became required")
+	}
+
+	oldContent, _ := oldBody["content"].(map[string]any)
+	newContent, _ := newBody["content"].(map[string]any)
+	d.diffContent(scope, pointer+"/content", oldContent, newContent)
+}
+
+func (d *differ) diffContent(scope diffScope, pointer string, oldContent, newContent map[string]any) {
+	removed, added := RequestMediaTypeRemoved, RequestMediaTypeAdded
+	if !scope.request {
+		removed, added = ResponseMediaTypeRemoved, ResponseMediaTypeAdded
+	}
+
+	for _, mime := range sortedKeys(oldContent) {
+		mediaPointer := pointer + "/" + godantic.EscapeJsonPointer(mime)
+		newMedia, found := newContent[mime].(map[string]any)
+		if !found {
+			d.add(ChangeBreaking, removed, scope, mediaPointer, "media type '%s' was removed", mime)
+			continue
+		}
+		oldMedia, _ := oldContent[mime].(map[string]any)
+		d.diffSchema(scope, mediaPointer+"/schema", oldMedia["schema"], newMedia["schema"], make(map[string]bool))
+	}
+	for _, mime := range sortedKeys(newContent) {
+		if _, found := oldContent[mime]; !found {
+			d.add(ChangeNonBreaking, added, scope, pointer+"/"+godantic.EscapeJsonPointer(mime), "media type '%s' was added", mime)
+		}
+	}
+}
+
+// diffSecurity 比较认证要求, 仅当原本无需认证的操作新增了认证要求时视为不兼容
+func (d *differ) diffSecurity(scope diffScope, pointer string, oldValue, newValue any) {
+	oldList, _ := oldValue.([]any)
+	newList, _ := newValue.([]any)
+	if len(oldList) == 0 && len(newList) > 0 {
+		d.add(ChangeBreaking, SecurityRequirementAdded, scope, pointer, "security requirement was added")
+	} else if len(oldList) > 0 && len(newList) == 0 {
+		d.add(ChangeNonBreaking, SecurityRequirementRemoved, scope, pointer, "security requirement was removed")
+	}
+}
+
+// diffSchema 比较两个模型, 请求中类型改变、枚举值减少、新增必需字段为不兼容的变更,
+// 响应中类型改变、枚举值增加、移除字段或字段变为非必需为不兼容的变更
+//
+//	@param	visited	map[string]bool	已比较的引用, 避免循环引用
+func (d *differ) diffSchema(scope diffScope, pointer string, oldValue, newValue any, visited map[string]bool) {
+	if oldValue == nil || newValue == nil {
+		return
+	}
+	refKey := schemaRef(oldValue) + "|" + schemaRef(newValue)
+	if refKey != "|" {
+		if visited[refKey] {
+			return
+		}
+		visited[refKey] = true
+	}
+	oldSchema, newSchema := d.resolveSchema(d.older, oldValue), d.resolveSchema(d.newer, newValue)
+
+	oldTypes, newTypes := schemaTypesOf(oldSchema), schemaTypesOf(newSchema)
+	if len(oldTypes) > 0 && len(newTypes) > 0 && strings.Join(oldTypes, ",") != strings.Join(newTypes, ",") {
+		d.add(ChangeBreaking, TypeChanged, scope, pointer+"/type", "type changed from '%s' to '%s'",
+			strings.Join(oldTypes, ","), strings.Join(newTypes, ","))
+		return // 类型已改变, 无需继续比较
+	}
+
+	d.diffEnum(scope, pointer+"/enum", oldSchema["enum"], newSchema["enum"])
+
+	if oldItems, found := oldSchema["items"]; found {
+		d.diffSchema(scope, pointer+"/items", oldItems, newSchema["items"], visited)
+	}
+
+	oldProps, _ := oldSchema["properties"].(map[string]any)
+	newProps, _ := newSchema["properties"].(map[string]any)
+	oldRequired, newRequired := stringSet(oldSchema["required"]), stringSet(newSchema["required"])
+
+	for _, name := range sortedKeys(oldProps) {
+		propPointer := pointer + "/properties/" + godantic.EscapeJsonPointer(name)
+		newProp, found := newProps[name]
+		if !found {
+			if scope.request {
+				d.add(ChangeNonBreaking, RequestPropertyRemoved, scope, propPointer, "request property '%s' was removed", name)
+			} else {
+				d.add(ChangeBreaking, ResponsePropertyRemoved, scope, propPointer, "response property '%s' was removed", name)
+			}
+			continue
+		}
+
+		switch {
+		case scope.request && !oldRequired[name] && newRequired[name]:
+			d.add(ChangeBreaking, RequestPropertyRequired, scope, propPointer, "request property '%s' became required", name)
+		case scope.request && oldRequired[name] && !newRequired[name]:
+			d.add(ChangeNonBreaking, RequestPropertyOptional, scope, propPointer, "request property '%s' became optional", name)
+		case !scope.request && oldRequired[name] && !newRequired[name]:
+			d.add(ChangeBreaking, ResponsePropertyOptional, scope, propPointer, "response property '%s' became optional", name)
+		case !scope.request && !oldRequired[name] && newRequired[name]:
+			d.add(ChangeNonBreaking, ResponsePropertyRequired, scope, propPointer, "response property '%s' became required", name)
+		}
+		d.diffSchema(scope, propPointer, oldProps[name], newProp, visited)
+	}
+
+	for _, name := range sortedKeys(newProps) {
+		if _, found := oldProps[name]; found {
+			continue
+		}
+		propPointer := pointer + "/properties/" + godantic.EscapeJsonPointer(name)
+		switch {
+		case !scope.request:
+			d.add(ChangeNonBreaking, ResponsePropertyAdded, scope, propPointer, "response property '%s' was added", name)
+		case newRequired[name]:
+			d.add(ChangeBreaking, RequestPropertyAdded, scope, propPointer, "required request property '%s' was added", name)
+		default:
+			d.add(ChangeNonBreaking, RequestPropertyAdded, scope, propPointer, "optional request property '%s' was added", name)
+		}
+	}
+}
+
+// diffEnum 比较枚举值, 请求中减少枚举值或响应中增加枚举值为不兼容的变更
+func (d *differ) diffEnum(scope diffScope, pointer string, oldValue, newValue any) {
+	oldList, _ := oldValue.([]any)
+	newList, _ := newValue.([]any)
+	if len(oldList) == 0 && len(newList) == 0 {
+		return
+	}
+
+	oldSet, newSet := valueSet(oldList), valueSet(newList)
+	var removed, added []string
+	for _, v := range sortedSetKeys(oldSet) {
+		if !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range sortedSetKeys(newSet) {
+		if !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+
+	// 原本无枚举限制, 新增限制相当于减少了取值范围, 反之亦然
+	narrowed := len(removed) > 0 || (len(oldList) == 0 && len(newList) > 0)
+	widened := len(oldList) > 0 && (len(added) > 0 || len(newList) == 0)
+
+	if narrowed {
+		level := ChangeNonBreaking
+		if scope.request {
+			level = ChangeBreaking
+		}
+		if len(oldList) == 0 {
+			d.add(level, EnumNarrowed, scope, pointer, "enum [%s] was added", strings.Join(sortedSetKeys(newSet), ", "))
+		} else {
+			d.add(level, EnumNarrowed, scope, pointer, "enum values [%s] were removed", strings.Join(removed, ", "))
+		}
+	}
+	if widened {
+		level := ChangeBreaking
+		if scope.request {
+			level = ChangeNonBreaking
+		}
+		if len(newList) == 0 {
+			d.add(level, EnumWidened, scope, pointer, "enum was removed")
+		} else {
+			d.add(level, EnumWidened, scope, pointer, "enum values [%s] were added", strings.Join(added, ", "))
+		}
+	}
+}
+
+// resolve 解析文档内的引用对象
+func (d *differ) resolve(doc map[string]any, value any) map[string]any {
+	m, _ := value.(map[string]any)
+	for i := 0; i < 32 && m != nil; i++ { // 限制层数, 避免循环引用
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return m
+		}
+		var node any = doc
+		for _, token := range strings.Split(ref[2:], "/") {
+			parent, _ := node.(map[string]any)
+			node = parent[strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")]
+		}
+		m, _ = node.(map[string]any)
+	}
+	return m
+}
+
+// resolveSchema 解析模型引用, 仅包含一个元素的 allOf 视为引用
+func (d *differ) resolveSchema(doc map[string]any, value any) map[string]any {
+	m := d.resolve(doc, value)
+	if list, ok := m["allOf"].([]any); ok && len(list) == 1 {
+		merged := make(map[string]any, len(m))
+		for k, v := range d.resolve(doc, list[0]) {
+			merged[k] = v
+		}
+		for k, v := range m {
+			if k != "allOf" {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+	return m
+}
+
+// schemaRef 模型引用的地址, 用于检测循环引用
+func schemaRef(value any) string {
+	m, _ := value.(map[string]any)
+	if ref, ok := m["$ref"].(string); ok {
+		return ref
+	}
+	if list, ok := m["allOf"].([]any); ok && len(list) == 1 {
+		return schemaRef(list[0])
+	}
+	return ""
+}
+
+// schemaTypesOf 模型的类型, 3.0 的 nullable 和 3.1 的 null 类型均视为可空, 不计入类型
+func schemaTypesOf(m map[string]any) []string {
+	var types []string
+	switch t := m["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+func stringSet(value any) map[string]bool {
+	set := make(map[string]bool)
+	list, _ := value.([]any)
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func valueSet(list []any) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, item := range list {
+		set[fmt.Sprint(item)] = true
+	}
+	return set
+}
+
+func sortedSetKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// httpMethods 路径对象中的操作, 按 OpenApi 规范中的顺序
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// sortedKeys 按字典序排列对象的键
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diffSpec 以路由和模型构造一个最小的 3.0 文档
+func diffSpec(paths, schemas string) []byte {
+	return []byte(`{"openapi": "3.0.3", "info": {"title": "diff", "version": "1.0.0"},
+		"paths": {` + paths + `}, "components": {"schemas": {` + schemas + `}}}`)
+}
+
+// diffOperation 构造一个操作, 参数为 parameters 数组、requestBody 对象和 responses 对象的内容
+func diffOperation(params, body, responses string) string {
+	op := `{"parameters": [` + params + `], "responses": {` + responses + `}`
+	if body != "" {
+		op += `, "requestBody": ` + body
+	}
+	return op + `}`
+}
+
+const (
+	diffOK       = `"200": {"description": "OK"}`
+	diffNotFound = `"404": {"description": "Not Found"}`
+	diffUser     = `"User": {"type": "object", "required": ["name"], "properties": {
+		"name": {"type": "string"}, "age": {"type": "integer"}, "role": {"type": "string", "enum": ["admin", "user"]}}}`
+)
+
+func diffJsonBody(schema string) string {
+	return `{"required": true, "content": {"application/json": {"schema": ` + schema + `}}}`
+}
+
+func diffJsonResponse(schema string) string {
+	return `"200": {"description": "OK", "content": {"application/json": {"schema": ` + schema + `}}}`
+}
+
+func TestDiff(t *testing.T) {
+	userRef := `{"$ref": "#/components/schemas/User"}`
+	query := func(required bool, schema string) string {
+		bs, _ := json.Marshal(map[string]any{"name": "page", "in": "query", "required": required, "schema": json.RawMessage(schema)})
+		return string(bs)
+	}
+
+	tests := []struct {
+		name     string
+		older    []byte
+		newer    []byte
+		kinds    []string
+		breaking bool
+	}{
+		{
+			name:     "path removed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}, "/b": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			kinds:    []string{PathRemoved},
+			breaking: true,
+		},
+		{
+			name:     "path added",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}, "/b": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			kinds:    []string{PathAdded},
+			breaking: false,
+		},
+		{
+			name:     "method removed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`, "post": `+diffOperation("", "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			kinds:    []string{OperationRemoved},
+			breaking: true,
+		},
+		{
+			name:     "parameter became required",
+			older:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "integer"}`), "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(true, `{"type": "integer"}`), "", diffOK)+`}`, ""),
+			kinds:    []string{ParameterBecameRequired},
+			breaking: true,
+		},
+		{
+			name:     "required parameter added",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(true, `{"type": "integer"}`), "", diffOK)+`}`, ""),
+			kinds:    []string{ParameterAdded},
+			breaking: true,
+		},
+		{
+			name:     "optional parameter added",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "integer"}`), "", diffOK)+`}`, ""),
+			kinds:    []string{ParameterAdded},
+			breaking: false,
+		},
+		{
+			name:  "request property became required",
+			older: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffOK)+`}`, diffUser),
+			newer: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffOK)+`}`,
+				strings.Replace(diffUser, `["name"]`, `["name", "age"]`, 1)),
+			kinds:    []string{RequestPropertyRequired},
+			breaking: true,
+		},
+		{
+			name:  "required request property added",
+			older: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffOK)+`}`, diffUser),
+			newer: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffOK)+`}`,
+				strings.Replace(strings.Replace(diffUser, `["name"]`, `["name", "email"]`, 1),
+					`"properties": {`, `"properties": {"email": {"type": "string"}, `, 1)),
+			kinds:    []string{RequestPropertyAdded},
+			breaking: true,
+		},
+		{
+			name:     "request enum narrowed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "string", "enum": ["a", "b"]}`), "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "string", "enum": ["a"]}`), "", diffOK)+`}`, ""),
+			kinds:    []string{EnumNarrowed},
+			breaking: true,
+		},
+		{
+			name:     "response enum narrowed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, diffUser),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, strings.Replace(diffUser, `["admin", "user"]`, `["admin"]`, 1)),
+			kinds:    []string{EnumNarrowed},
+			breaking: false,
+		},
+		{
+			name:     "response enum widened",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, diffUser),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, strings.Replace(diffUser, `["admin", "user"]`, `["admin", "user", "guest"]`, 1)),
+			kinds:    []string{EnumWidened},
+			breaking: true,
+		},
+		{
+			name:     "parameter type changed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "integer"}`), "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "string"}`), "", diffOK)+`}`, ""),
+			kinds:    []string{TypeChanged},
+			breaking: true,
+		},
+		{
+			name:     "response property type changed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, diffUser),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, strings.Replace(diffUser, `"age": {"type": "integer"}`, `"age": {"type": "string"}`, 1)),
+			kinds:    []string{TypeChanged},
+			breaking: true,
+		},
+		{
+			name:     "response removed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK+", "+diffNotFound)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, ""),
+			kinds:    []string{ResponseRemoved},
+			breaking: true,
+		},
+		{
+			name:     "response property removed",
+			older:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, diffUser),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation("", "", diffJsonResponse(userRef))+`}`, strings.Replace(diffUser, `"age": {"type": "integer"}, `, "", 1)),
+			kinds:    []string{ResponsePropertyRemoved},
+			breaking: true,
+		},
+		{
+			name:  "ref renamed",
+			older: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffJsonResponse(userRef))+`}`, diffUser),
+			newer: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(`{"$ref": "#/components/schemas/Account"}`),
+				diffJsonResponse(`{"$ref": "#/components/schemas/Account"}`))+`}`, strings.Replace(diffUser, `"User"`, `"Account"`, 1)),
+			kinds:    nil,
+			breaking: false,
+		},
+		{
+			name:  "ref wrapped in allOf",
+			older: diffSpec(`"/a": {"post": `+diffOperation("", diffJsonBody(userRef), diffJsonResponse(userRef))+`}`, diffUser),
+			newer: diffSpec(`"/a": {"post": `+diffOperation("",
+				diffJsonBody(`{"allOf": [{"$ref": "#/components/schemas/Account"}], "description": "账户"}`),
+				diffJsonResponse(`{"allOf": [{"$ref": "#/components/schemas/Account"}]}`))+`}`,
+				strings.Replace(diffUser, `"User"`, `"Account"`, 1)),
+			kinds:    nil,
+			breaking: false,
+		},
+		{
+			name:     "nullable to null type",
+			older:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": "string", "nullable": true}`), "", diffOK)+`}`, ""),
+			newer:    diffSpec(`"/a": {"get": `+diffOperation(query(false, `{"type": ["string", "null"]}`), "", diffOK)+`}`, ""),
+			kinds:    nil,
+			breaking: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Diff(tt.older, tt.newer)
+			if err != nil {
+				t.Fatalf("diff: %v", err)
+			}
+
+			var kinds []string
+			for _, c := range report.Changes {
+				kinds = append(kinds, c.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				bs, _ := json.Marshal(report.Changes)
+				t.Fatalf("expected changes %v, got %s", tt.kinds, bs)
+			}
+			if report.Breaking != tt.breaking || len(report.BreakingChanges()) != report.BreakingNum {
+				t.Errorf("expected breaking %v, got %v (%d)", tt.breaking, report.Breaking, report.BreakingNum)
+			}
+		})
+	}
+}
+
+func TestDiffChangeLocation(t *testing.T) {
+	older := diffSpec(`"/users/{id}": {"get": `+diffOperation("", "", diffJsonResponse(`{"$ref": "#/components/schemas/User"}`))+`}`, diffUser)
+	newer := diffSpec(`"/users/{id}": {"get": `+diffOperation("", "", diffJsonResponse(`{"$ref": "#/components/schemas/User"}`))+`}`,
+		strings.Replace(diffUser, `["name"]`, `[]`, 1))
+
+	report, err := Diff(older, newer)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	want := &Change{
+		Level:   ChangeBreaking,
+		Kind:    ResponsePropertyOptional,
+		Path:    "/users/{id}",
+		Method:  "GET",
+		Pointer: "#/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/properties/name",
+		Message: "response property 'name' became optional",
+	}
+	if len(report.Changes) != 1 || !reflect.DeepEqual(report.Changes[0], want) {
+		bs, _ := json.Marshal(report.Changes)
+		t.Errorf("expected %+v, got %s", want, bs)
+	}
+}
+
+func TestDiffYaml(t *testing.T) {
+	older := []byte(`
+openapi: 3.1.0
+info: {title: diff, version: 1.0.0}
+paths:
+  /a:
+    get:
+      responses:
+        200: {description: OK}
+        404: {description: Not Found}
+`)
+	newer := diffSpec(`"/a": {"get": `+diffOperation("", "", diffOK)+`}`, "")
+
+	report, err := Diff(older, newer)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Kind != ResponseRemoved ||
+		report.Changes[0].Pointer != "#/paths/~1a/get/responses/404" {
+		bs, _ := json.Marshal(report.Changes)
+		t.Errorf("expected response 404 removed, got %s", bs)
+	}
+
+	if _, err = Diff([]byte(`[]`), newer); err == nil {
+		t.Errorf("expected an error for a document that is not an object")
+	}
+}
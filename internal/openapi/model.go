@@ -3,21 +3,22 @@ package openapi
 import (
 	"github.com/Chendemo12/flaskgo/internal/godantic"
 	"github.com/Chendemo12/functools/helper"
+	"strings"
 )
 
 // Contact 联系方式, 显示在 info 字段内部
 // 无需重写序列化方法
 type Contact struct {
-	Name  string `json:"name" description:"姓名/名称"`
-	Url   string `json:"url" description:"链接"`
-	Email string `json:"email" description:"联系方式"`
+	Name  string `json:"name,omitempty" description:"姓名/名称"`
+	Url   string `json:"url,omitempty" description:"链接"`
+	Email string `json:"email,omitempty" description:"联系方式"`
 }
 
 // License 权利证书, 显示在 info 字段内部
 // 无需重写序列化方法
 type License struct {
 	Name string `json:"name" description:"名称"`
-	Url  string `json:"url" description:"链接"`
+	Url  string `json:"url,omitempty" description:"链接"`
 }
 
 // Info 文档说明信息
@@ -28,7 +29,7 @@ type Info struct {
 	Description    string  `json:"description" description:"显示在标题下方的说明"`
 	Contact        Contact `json:"contact" description:"联系方式"`
 	License        License `json:"license" description:"许可证"`
-	TermsOfService string  `json:"termsOfService" description:"服务条款(不常用)"`
+	TermsOfService string  `json:"termsOfService,omitempty" description:"服务条款(不常用)"`
 }
 
 // Reference 引用模型,用于模型字段和路由之间互相引用
@@ -53,7 +54,9 @@ type ComponentScheme struct {
 // Components openapi 的模型部分
 // 需要重写序列化方法
 type Components struct {
-	Scheme []*ComponentScheme `json:"scheme" description:"模型文档"`
+	Scheme          []*ComponentScheme         `json:"scheme" description:"模型文档"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" description:"认证方案"`
+	ProblemDetails  bool                       `json:"-" description:"错误消息是否为RFC 7807格式"`
 }
 
 // MarshalJSON 重载序列化方法
@@ -76,10 +79,17 @@ func (c *Components) MarshalJSON() ([]byte, error) {
 
 	// 记录内置错误类型文档
 	m["ValidationError"] = validationErrorDefinition
-	m["HTTPValidationError"] = validationErrorResponseDefinition
+	if c.ProblemDetails {
+		m[ProblemDetailsName] = problemDetailsDefinition
+	} else {
+		m[HttpValidationErrorName] = validationErrorResponseDefinition
+	}
 	// delete
 	//m["CustomValidationError"] = customErrorDefinition
 
+	if len(c.SecuritySchemes) > 0 {
+		return helper.DefaultJsonMarshal(map[string]any{"schemas": m, "securitySchemes": c.SecuritySchemes})
+	}
 	return helper.DefaultJsonMarshal(map[string]any{"schemas": m})
 }
 
@@ -91,6 +101,52 @@ func (c *Components) AddModel(m godantic.SchemaIface) {
 	})
 }
 
+// AddSecurityScheme 添加一个认证方案, 同名方案仅保留第一个
+func (c *Components) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	if c.SecuritySchemes == nil {
+		c.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+	if _, ok := c.SecuritySchemes[name]; !ok {
+		c.SecuritySchemes[name] = scheme
+	}
+}
+
+type SecuritySchemeType string
+
+const (
+	SecurityTypeAPIKey        SecuritySchemeType = "apiKey"
+	SecurityTypeHTTP          SecuritySchemeType = "http"
+	SecurityTypeOAuth2        SecuritySchemeType = "oauth2"
+	SecurityTypeOpenIdConnect SecuritySchemeType = "openIdConnect"
+)
+
+// SecurityScheme 认证方案文档，位于 components.securitySchemes
+type SecurityScheme struct {
+	Type         SecuritySchemeType `json:"type" description:"认证类型"`
+	Description  string             `json:"description,omitempty" description:"说明"`
+	Name         string             `json:"name,omitempty" description:"参数名称, 仅 apiKey 有效"`
+	In           APIKeyIn           `json:"in,omitempty" description:"参数位置, 仅 apiKey 有效"`
+	Scheme       string             `json:"scheme,omitempty" description:"认证方式, 如 basic 和 bearer, 仅 http 有效"`
+	BearerFormat string             `json:"bearerFormat,omitempty" description:"令牌格式, 如 JWT, 仅 http bearer 有效"`
+	Flows        *OAuthFlows        `json:"flows,omitempty" description:"授权流程, 仅 oauth2 有效"`
+}
+
+// OAuthFlows OAuth2 授权流程
+type OAuthFlows struct {
+	Password *OAuthFlow `json:"password,omitempty" description:"密码模式"`
+}
+
+// OAuthFlow OAuth2 授权流程配置
+type OAuthFlow struct {
+	Scopes           map[string]string `json:"scopes" description:"授权范围及其说明"`
+	AuthorizationUrl string            `json:"authorizationUrl,omitempty" description:"授权地址"`
+	TokenUrl         string            `json:"tokenUrl,omitempty" description:"令牌地址"`
+	RefreshUrl       string            `json:"refreshUrl,omitempty" description:"刷新令牌地址"`
+}
+
+// SecurityRequirement 路由的认证要求, 键为认证方案名称, 值为所需的授权范围
+type SecurityRequirement map[string][]string
+
 type ParameterInType string
 
 const (
@@ -102,19 +158,69 @@ const (
 
 // ParameterBase 各种参数的基类
 type ParameterBase struct {
-	Description string                   `json:"description,omitempty" description:"说明"`
-	Type        godantic.OpenApiDataType `json:"type" description:"数据类型"`
-	Required    bool                     `json:"required" description:"是否必须"`
-	Deprecated  bool                     `json:"deprecated" description:"是否禁用"`
+	Description string `json:"description,omitempty" description:"说明"`
+	Required    bool   `json:"required" description:"是否必须, 路径参数恒为true"`
+	Deprecated  bool   `json:"deprecated" description:"是否禁用"`
+}
+
+// ParameterSchema 参数的数据类型定义
+type ParameterSchema struct {
+	Default any                      `json:"default,omitempty" description:"默认值"`
+	Items   map[string]any           `json:"items,omitempty" description:"数组元素类型"`
+	Title   string                   `json:"title,omitempty" description:"标题"`
+	Type    godantic.OpenApiDataType `json:"type" description:"数据类型"`
+	Format  string                   `json:"format,omitempty" description:"数据格式"`
+	Enum    []any                    `json:"enum,omitempty" description:"可选项"`
+	// 校验约束, 由字段标签生成, 参考 godantic.SchemaConstraints
+	Minimum          any    `json:"minimum,omitempty" description:"最小值"`
+	Maximum          any    `json:"maximum,omitempty" description:"最大值"`
+	ExclusiveMinimum any    `json:"exclusiveMinimum,omitempty" description:"是否不包含最小值"`
+	ExclusiveMaximum any    `json:"exclusiveMaximum,omitempty" description:"是否不包含最大值"`
+	MinLength        any    `json:"minLength,omitempty" description:"字符串最小长度"`
+	MaxLength        any    `json:"maxLength,omitempty" description:"字符串最大长度"`
+	MinItems         any    `json:"minItems,omitempty" description:"数组最小长度"`
+	MaxItems         any    `json:"maxItems,omitempty" description:"数组最大长度"`
+	Pattern          string `json:"pattern,omitempty" description:"字符串正则表达式"`
+}
+
+// SetConstraints 设置校验约束
+//
+//	@param	m	map[string]any	校验约束, 如: {"minimum": 1, "maxLength": 10}
+func (s *ParameterSchema) SetConstraints(m map[string]any) {
+	for k, v := range m {
+		switch k {
+		case "minimum":
+			s.Minimum = v
+		case "maximum":
+			s.Maximum = v
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum = v
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum = v
+		case "minLength":
+			s.MinLength = v
+		case "maxLength":
+			s.MaxLength = v
+		case "minItems":
+			s.MinItems = v
+		case "maxItems":
+			s.MaxItems = v
+		case "pattern":
+			s.Pattern, _ = v.(string)
+		case "format":
+			if s.Format == "" {
+				s.Format, _ = v.(string)
+			}
+		}
+	}
 }
 
-// Parameter 路径参数或者查询参数
+// Parameter 路径参数或者查询参数, 参数的类型, 标题和默认值均由 Schema 描述
 type Parameter struct {
-	Default any `json:"default,omitempty" description:"默认值"`
+	Schema *ParameterSchema `json:"schema" description:"数据类型定义"`
 	ParameterBase
-	Title string          `json:"title"`
-	Name  string          `json:"name" description:"名称"`
-	In    ParameterInType `json:"in" description:"参数位置"`
+	Name string          `json:"name" description:"名称"`
+	In   ParameterInType `json:"in" description:"参数位置"`
 }
 
 type ModelContentSchema interface {
@@ -157,12 +263,9 @@ type ObjectModelContentSchema struct {
 	Reference
 }
 
+// Schema 关联类型, $ref 不允许与其他属性并列
 func (s ObjectModelContentSchema) Schema() map[string]any {
-	return map[string]any{
-		"title":          s.Title,
-		"type":           godantic.ObjectType,
-		godantic.RefName: godantic.RefPrefix + s.Name,
-	}
+	return map[string]any{godantic.RefName: godantic.RefPrefix + s.Name}
 }
 
 // RequestBody 路由 请求体模型文档
@@ -173,28 +276,32 @@ type RequestBody struct {
 
 // PathModelContent 路由中请求体 RequestBody 和 响应体中返回值 Responses 模型
 type PathModelContent struct {
-	Schema   ModelContentSchema  `json:"schema" description:"模型引用文档"`
-	MIMEType ApplicationMIMEType `json:"-"`
+	Schema         ModelContentSchema    `json:"schema" description:"模型引用文档"`
+	MIMEType       ApplicationMIMEType   `json:"-"`
+	ExtraMIMETypes []ApplicationMIMEType `json:"-" description:"其他的数据格式, 与 MIMEType 共用同一个模型"`
 }
 
 // MarshalJSON 自定义序列化
 func (p *PathModelContent) MarshalJSON() ([]byte, error) {
 	m := make(map[string]any)
 	m[string(p.MIMEType)] = map[string]any{"schema": p.Schema.Schema()}
+	for _, mime := range p.ExtraMIMETypes {
+		m[string(mime)] = map[string]any{"schema": p.Schema.Schema()}
+	}
 
 	return helper.DefaultJsonMarshal(m)
 }
 
 // Response 路由返回体，包含了返回状态码，状态码说明和返回值模型
 type Response struct {
-	Content     *PathModelContent `json:"content" description:"返回值模型"`
+	Content     *PathModelContent `json:"content,omitempty" description:"返回值模型"`
 	Description string            `json:"description" description:"说明"`
 	StatusCode  int               `json:"-" description:"状态码"`
 }
 
 // Operation 路由HTTP方法: Get/Post/Patch/Delete 等操作方法
 type Operation struct {
-	Tags        []string `json:"tags" description:"路由标签"`
+	Tags        []string `json:"tags,omitempty" description:"路由标签"`
 	Summary     string   `json:"summary" description:"摘要描述"`
 	Description string   `json:"description" description:"说明"`
 	OperationId string   `json:"operationId,omitempty" description:"唯一ID"` // no use, keep
@@ -205,6 +312,8 @@ type Operation struct {
 	// 响应文档，对于任一个路由，均包含2个响应实例：200 + 422， 通过函数 MakeOperationResponses 构建
 	Responses  []*Response `json:"responses" description:"响应体"`
 	Deprecated bool        `json:"deprecated" description:"是否禁用"`
+	// 认证要求，任意一个认证要求满足即可
+	Security []SecurityRequirement `json:"security,omitempty" description:"认证要求"`
 }
 
 // MarshalJSON 重写序列化方法，修改 Responses 和 RequestBody 字段
@@ -220,8 +329,9 @@ func (o *Operation) MarshalJSON() ([]byte, error) {
 	orm.Description = o.Description
 	orm.OperationId = o.OperationId
 	orm.Parameters = o.Parameters
-	orm.RequestBody = o.RequestBody // TODO:
+	orm.RequestBody = o.RequestBody
 	orm.Deprecated = o.Deprecated
+	orm.Security = o.Security
 
 	orm.Responses = make(map[int]*Response)
 	for _, r := range o.Responses {
@@ -269,6 +379,7 @@ type OpenApi struct {
 	Components  *Components `json:"components" description:"模型文档"`
 	Paths       *Paths      `json:"paths" description:"路由列表,同一路由存在多个方法文档"`
 	Version     string      `json:"openapi" description:"Open API版本号"`
+	Dialect     string      `json:"jsonSchemaDialect,omitempty" description:"模型方言, 仅 3.1 版本"`
 	cache       []byte
 	initialized bool
 }
@@ -279,6 +390,18 @@ func (o *OpenApi) AddDefinition(model godantic.SchemaIface) *OpenApi {
 	return o
 }
 
+// UseProblemDetails 错误消息采用 RFC 7807 格式, 以 ProblemDetails 模型代替 HTTPValidationError 模型
+func (o *OpenApi) UseProblemDetails() *OpenApi {
+	o.Components.ProblemDetails = true
+	return o
+}
+
+// AddSecurityScheme 添加一个认证方案
+func (o *OpenApi) AddSecurityScheme(name string, scheme *SecurityScheme) *OpenApi {
+	o.Components.AddSecurityScheme(name, scheme)
+	return o
+}
+
 // AddPathItem 添加一个路由对象
 func (o *OpenApi) AddPathItem(item *PathItem) {
 	// 修改路径格式
@@ -298,10 +421,43 @@ func (o *OpenApi) QueryPathItem(path string) *PathItem {
 	return nil
 }
 
+// SetVersion 设置文档版本, 支持 3.0.3 和 3.1.0
+//
+//	@param	version	string	文档版本, Version303 或 Version310
+func (o *OpenApi) SetVersion(version string) *OpenApi {
+	o.Version = version
+	o.Dialect = ""
+	if o.Is31() {
+		o.Dialect = JsonSchemaDialect
+	}
+	o.initialized = false
+	return o
+}
+
+// Is31 文档是否为 3.1 版本
+func (o *OpenApi) Is31() bool { return strings.HasPrefix(o.Version, "3.1") }
+
+// Marshal 序列化为 json 格式的文档, 字段按名称排序, 3.1 版本的文档会转换为 JSON Schema 2020-12 格式
+func (o *OpenApi) Marshal() ([]byte, error) {
+	bs, err := helper.DefaultJson.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJson(bs, o.Is31())
+}
+
+// MarshalYAML 序列化为 yaml 格式的文档, 字段顺序与 json 格式一致
+func (o *OpenApi) MarshalYAML() ([]byte, error) {
+	bs, err := o.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return JsonToYaml(bs)
+}
+
 // RecreateDocs 重建Swagger 文档
 func (o *OpenApi) RecreateDocs() *OpenApi {
-	bs, err := helper.DefaultJson.Marshal(o)
-	if err == nil {
+	if bs, err := o.Marshal(); err == nil {
 		o.cache = bs
 	}
 
@@ -309,7 +465,7 @@ func (o *OpenApi) RecreateDocs() *OpenApi {
 	return o
 }
 
-// Schema Swagger 文档, 并非完全符合 OpenApi 文档规范
+// Schema Swagger 文档, 可通过 Validate 检查其是否符合 OpenApi 文档规范
 func (o *OpenApi) Schema() []byte {
 	if !o.initialized {
 		o.RecreateDocs()
@@ -5,7 +5,12 @@ import (
 	"net/http"
 )
 
-const ApiVersion = "3.0.2"
+const (
+	Version303        = "3.0.3"                                          // OpenApi 3.0.3
+	Version310        = "3.1.0"                                          // OpenApi 3.1.0, 模型采用 JSON Schema 2020-12
+	JsonSchemaDialect = "https://spec.openapis.org/oas/3.1/dialect/base" // 3.1 文档缺省的模型方言
+	ApiVersion        = Version303                                       // 缺省的文档版本
+)
 
 // 用于swagger的一些静态文件，来自FastApi
 const (
@@ -14,6 +19,7 @@ const (
 	SwaggerJsUrl      = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@4/swagger-ui-bundle.js"
 	RedocJsUrl        = "https://cdn.jsdelivr.net/npm/redoc@next/bundles/redoc.standalone.js"
 	RedocFaviconUrl   = "https://fastapi.tiangolo.com/img/favicon.png"
+	RedocFontsUrl     = "https://fonts.googleapis.com/css?family=Montserrat:300,400,700|Roboto:300,400,700"
 	JsonUrl           = "openapi.json"
 )
 
@@ -35,6 +41,7 @@ const (
 	MIMEApplicationForm            ApplicationMIMEType = "application/x-www-form-urlencoded"
 	MIMEOctetStream                ApplicationMIMEType = "application/octet-stream"
 	MIMEMultipartForm              ApplicationMIMEType = "multipart/form-data"
+	MIMEApplicationProblemJSON     ApplicationMIMEType = "application/problem+json"
 	MIMETextXMLCharsetUTF8         ApplicationMIMEType = "text/xml; charset=utf-8"
 	MIMETextHTMLCharsetUTF8        ApplicationMIMEType = "text/html; charset=utf-8"
 	MIMETextPlainCharsetUTF8       ApplicationMIMEType = "text/plain; charset=utf-8"
@@ -49,6 +56,7 @@ const (
 	ValidationErrorName       string = "ValidationError"
 	HttpValidationErrorName   string = "HTTPValidationError"
 	CustomValidationErrorName string = "CustomValidationError"
+	ProblemDetailsName        string = "ProblemDetails"
 )
 
 // 422 表单验证错误模型
@@ -81,6 +89,25 @@ var validationErrorResponseDefinition = dict{
 	},
 }
 
+// RFC 7807 错误消息, 其中 invalid-params 为参数校验错误
+var problemDetailsDefinition = dict{
+	"title":    ProblemDetailsName,
+	"type":     godantic.ObjectType,
+	"required": []string{"type", "title", "status"},
+	"properties": dict{
+		"type":     dict{"title": "Type", "type": "string", "format": "uri-reference", "default": "about:blank"},
+		"title":    dict{"title": "Title", "type": "string"},
+		"status":   dict{"title": "Status", "type": "integer"},
+		"detail":   dict{"title": "Detail", "type": "string"},
+		"instance": dict{"title": "Instance", "type": "string", "format": "uri-reference"},
+		"invalid-params": dict{
+			"title": "Invalid Params",
+			"type":  "array",
+			"items": dict{"$ref": godantic.RefPrefix + ValidationErrorName},
+		},
+	},
+}
+
 // 自定义错误消息
 var customErrorDefinition = dict{
 	"title":    CustomValidationErrorName,
@@ -120,3 +147,21 @@ var Resp422 = &Response{
 		},
 	},
 }
+
+// Resp422Problem 以 application/problem+json 格式返回的请求体校验错误
+var Resp422Problem = &Response{
+	StatusCode:  http.StatusUnprocessableEntity,
+	Description: http.StatusText(http.StatusUnprocessableEntity),
+	Content: &PathModelContent{
+		MIMEType: MIMEApplicationProblemJSON,
+		Schema: &ObjectModelContentSchema{
+			BaseModelContentSchema: BaseModelContentSchema{
+				Title: ProblemDetailsName,
+				Type:  godantic.ObjectType,
+			},
+			Reference: Reference{
+				Name: ProblemDetailsName,
+			},
+		},
+	},
+}
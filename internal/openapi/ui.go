@@ -7,7 +7,15 @@ var swaggerUiDefaultParameters = map[string]string{
 	"showCommonExtensions": "true",
 }
 
-func MakeSwaggerUiHtml(title, openapiUrl, jsUrl, cssUrl, faviconUrl string) string {
+// MakeSwaggerUiHtml 生成 swagger 在线调试页面
+//
+//	@param	title				string	页面标题
+//	@param	openapiUrl			string	openapi 文档路由, 如: /openapi.json
+//	@param	jsUrl				string	swagger-ui-bundle.js 地址
+//	@param	cssUrl				string	swagger-ui.css 地址
+//	@param	faviconUrl			string	页面图标地址
+//	@param	oauth2RedirectUrl	string	OAuth2 认证的回调路由, 如: /docs/oauth2-redirect
+func MakeSwaggerUiHtml(title, openapiUrl, jsUrl, cssUrl, faviconUrl, oauth2RedirectUrl string) string {
 	indexPage := `
 	<!DOCTYPE html>
 	<html>
@@ -22,13 +30,13 @@ func MakeSwaggerUiHtml(title, openapiUrl, jsUrl, cssUrl, faviconUrl string) stri
 		<script src="` + jsUrl + `"></script>
 		<script>
 		const ui = SwaggerUIBundle({
-		url: './` + openapiUrl + `',
+		url: '` + openapiUrl + `',
 	`
 	for k, v := range swaggerUiDefaultParameters {
 		indexPage = indexPage + `"` + k + `": ` + v + ",\n"
 	}
 
-	indexPage = indexPage + "oauth2RedirectUrl: window.location.origin + '/docs/oauth2-redirect',\n"
+	indexPage = indexPage + "oauth2RedirectUrl: window.location.origin + '" + oauth2RedirectUrl + "',\n"
 
 	indexPage += ` presets: [
         SwaggerUIBundle.presets.apis,
@@ -42,25 +50,36 @@ func MakeSwaggerUiHtml(title, openapiUrl, jsUrl, cssUrl, faviconUrl string) stri
 	return indexPage
 }
 
-func MakeRedocUiHtml(title, openapiUrl, jsUrl, faviconUrl string) string {
+// MakeRedocUiHtml 生成 redoc 文档页面
+//
+//	@param	title		string	页面标题
+//	@param	openapiUrl	string	openapi 文档路由, 如: /openapi.json
+//	@param	jsUrl		string	redoc.standalone.js 地址
+//	@param	faviconUrl	string	页面图标地址
+//	@param	fontsUrl	string	字体样式地址, 如 RedocFontsUrl, 为空则使用浏览器缺省字体
+func MakeRedocUiHtml(title, openapiUrl, jsUrl, faviconUrl, fontsUrl string) string {
+	fonts := ""
+	if fontsUrl != "" {
+		fonts = `<link href="` + fontsUrl + `" rel="stylesheet">`
+	}
 	indexPage := `
 	<!DOCTYPE html>
 	<html>
-	<head>= 
+	<head>
 		<title>` + title + ` </title>
 		<!-- needed for adaptive design -->
 		<meta charset="utf-8"/>
 		<meta name="viewport" content="width=device-width, initial-scale=1">
-		<link href="https://fonts.googleapis.com/css?family=Montserrat:300,400,700|Roboto:300,400,700" rel="stylesheet">
+		` + fonts + `
 		<link rel="shortcut icon" href="` + faviconUrl + `">
 		<!--
 			ReDoc doesn't change outer page styles
 		-->
 		<style>
-			body {{
+			body {
 				margin: 0;
 				padding: 0;
-			}}
+			}
 		</style>
 	</head>`
 
@@ -77,87 +96,8 @@ func MakeRedocUiHtml(title, openapiUrl, jsUrl, faviconUrl string) string {
 	return indexPage
 }
 
+// MakeOauth2RedirectHtml 生成 swagger OAuth2 认证的回调页面, 来自 swagger-ui-dist 的 oauth2-redirect.html
 func MakeOauth2RedirectHtml() string {
-	// copied from https://github.com/swagger-api/swagger-ui/blob/v4.14.0/dist/oauth2-redirect.html
-	return `    
-    html = """
-    <!doctype html>
-    <html lang="en-US">
-    <head>
-        <title>Swagger UI: OAuth2 Redirect</title>
-    </head>
-    <body>
-    <script>
-        'use strict';
-        function run () {
-            var oauth2 = window.opener.swaggerUIRedirectOauth2;
-            var sentState = oauth2.state;
-            var redirectUrl = oauth2.redirectUrl;
-            var isValid, qp, arr;
-
-            if (/code|token|error/.test(window.location.hash)) {
-                qp = window.location.hash.substring(1).replace('?', '&');
-            } else {
-                qp = location.search.substring(1);
-            }
-
-            arr = qp.split("&");
-            arr.forEach(function (v,i,_arr) { _arr[i] = '"' + v.replace('=', '":"') + '"';});
-            qp = qp ? JSON.parse('{' + arr.join() + '}',
-                    function (key, value) {
-                        return key === "" ? value : decodeURIComponent(value);
-                    }
-            ) : {};
-
-            isValid = qp.state === sentState;
-
-            if ((
-              oauth2.auth.schema.get("flow") === "accessCode" ||
-              oauth2.auth.schema.get("flow") === "authorizationCode" ||
-              oauth2.auth.schema.get("flow") === "authorization_code"
-            ) && !oauth2.auth.code) {
-                if (!isValid) {
-                    oauth2.errCb({
-                        authId: oauth2.auth.name,
-                        source: "auth",
-                        level: "warning",
-                        message: "Authorization may be unsafe, passed state was changed in server. The passed state wasn't returned from auth server."
-                    });
-                }
-
-                if (qp.code) {
-                    delete oauth2.state;
-                    oauth2.auth.code = qp.code;
-                    oauth2.callback({auth: oauth2.auth, redirectUrl: redirectUrl});
-                } else {
-                    let oauthErrorMsg;
-                    if (qp.error) {
-                        oauthErrorMsg = "["+qp.error+"]: " +
-                            (qp.error_description ? qp.error_description+ ". " : "no accessCode received from the server. ") +
-                            (qp.error_uri ? "More info: "+qp.error_uri : "");
-                    }
-
-                    oauth2.errCb({
-                        authId: oauth2.auth.name,
-                        source: "auth",
-                        level: "error",
-                        message: oauthErrorMsg || "[Authorization failed]: no accessCode received from the server."
-                    });
-                }
-            } else {
-                oauth2.callback({auth: oauth2.auth, token: qp, isValid: isValid, redirectUrl: redirectUrl});
-            }
-            window.close();
-        }
-
-        if (document.readyState !== 'loading') {
-            run();
-        } else {
-            document.addEventListener('DOMContentLoaded', function () {
-                run();
-            });
-        }
-    </script>
-    </body>
-    </html>`
+	bs, _ := StaticFile("oauth2-redirect.html")
+	return string(bs)
 }
@@ -1,15 +1,21 @@
 package openapi
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/Chendemo12/flaskgo/internal/godantic"
+	"gopkg.in/yaml.v3"
 	"net/http"
 	"strings"
 )
 
 // MakeOperationRequestBody 将路由中的 godantic.SchemaIface 转换成 openapi 的请求体 RequestBody
-func MakeOperationRequestBody(model godantic.SchemaIface) *RequestBody {
-	if model == nil {
-		return &RequestBody{}
+//
+//	@param	model		godantic.SchemaIface	请求体模型
+//	@param	mimeTypes	[]ApplicationMIMEType	请求体支持的数据格式, 缺省为 application/json, 文件上传模型恒为 multipart/form-data
+func MakeOperationRequestBody(model godantic.SchemaIface, mimeTypes ...ApplicationMIMEType) *RequestBody {
+	if model == nil { // 不存在请求体, 由于 content 不允许为空, 此时不生成 requestBody
+		return nil
 	}
 
 	r := &RequestBody{
@@ -19,6 +25,12 @@ func MakeOperationRequestBody(model godantic.SchemaIface) *RequestBody {
 			Schema:   nil,
 		},
 	}
+	if godantic.IsMultipart(model) { // 文件上传
+		r.Content.MIMEType = MIMEMultipartForm
+	} else if len(mimeTypes) > 0 {
+		r.Content.MIMEType = mimeTypes[0]
+		r.Content.ExtraMIMETypes = mimeTypes[1:]
+	}
 
 	bcs := BaseModelContentSchema{Title: model.SchemaName(), Type: model.SchemaType()}
 	switch model.SchemaType() {
@@ -54,38 +66,53 @@ func MakeOperationResponses(model godantic.SchemaIface) []*Response {
 
 	m := make([]*Response, 2) // 200 + 422
 	// 200 接口处注册的返回值
-	m[0] = &Response{
-		StatusCode:  http.StatusOK,
-		Description: http.StatusText(http.StatusOK),
-		Content: &PathModelContent{
-			MIMEType: MIMEApplicationJSON,
-			Schema:   nil,
-		},
-	}
+	m[0] = MakeOperationResponse(http.StatusOK, model, http.StatusText(http.StatusOK))
 	// 422 所有接口默认携带的请求体校验错误返回值
 	m[1] = Resp422
 
+	return m
+}
+
+// MakeOperationResponse 将指定状态码的 godantic.SchemaIface 转换成 openapi 的返回体 Response
+//
+//	@param	statusCode	int						响应状态码
+//	@param	model		godantic.SchemaIface	响应模型, 若为nil则此响应不包含返回值模型
+//	@param	description	string					响应说明
+func MakeOperationResponse(statusCode int, model godantic.SchemaIface, description string) *Response {
+	r := &Response{
+		StatusCode:  statusCode,
+		Description: description,
+		Content:     nil,
+	}
+	if model == nil {
+		return r
+	}
+
+	r.Content = &PathModelContent{
+		MIMEType: MIMEApplicationJSON,
+		Schema:   nil,
+	}
 	bcs := BaseModelContentSchema{Title: model.SchemaName(), Type: model.SchemaType()}
 	switch model.SchemaType() {
 	case godantic.ObjectType:
-		m[0].Content.Schema = ObjectModelContentSchema{
+		r.Content.Schema = ObjectModelContentSchema{
 			BaseModelContentSchema: bcs,
 			Reference: Reference{
 				Name: model.SchemaName(),
 			},
 		}
 	case godantic.ArrayType:
-		m[0].Content.Schema = ArrayModelContentSchema{
+		r.Content.Schema = ArrayModelContentSchema{
 			BaseModelContentSchema: bcs,
 			Items: Reference{
 				Name: model.SchemaName(),
 			},
 		}
 	default:
-		m[0].Content.Schema = bcs
+		r.Content.Schema = bcs
 	}
 
-	return m
+	return r
 }
 
 // NewOpenApi 构造一个新的 OpenApi 文档
@@ -99,12 +126,12 @@ func NewOpenApi(title, version, description string) *OpenApi {
 			TermsOfService: "",
 			Contact: Contact{
 				Name:  "FlaskGo",
-				Url:   "github.com/Chendemo12/flaskgo",
+				Url:   "https://github.com/Chendemo12/flaskgo",
 				Email: "chendemo12@gmail.com",
 			},
 			License: License{
 				Name: "FlaskGo",
-				Url:  "github.com/Chendemo12/flaskgo",
+				Url:  "https://github.com/Chendemo12/flaskgo",
 			},
 		},
 		Components:  &Components{Scheme: make([]*ComponentScheme, 0)},
@@ -150,17 +177,107 @@ func QModelToParameter(model *godantic.QModel) *Parameter {
 			Description: model.SchemaDesc(),
 			Required:    model.IsRequired(),
 			Deprecated:  false,
-			Type:        model.SchemaType(),
 		},
+		Name: model.SchemaName(),
+		In:   ParameterInType(model.Location()),
+	}
+	if p.In == InPath { // 路径参数必须为必选参数
+		p.Required = true
+	}
+
+	p.Schema = &ParameterSchema{
 		Title:   model.Title,
-		Name:    model.SchemaName(),
-		In:      InQuery,
+		Type:    model.SchemaType(),
+		Format:  model.SchemaFormat(),
 		Default: godantic.GetDefaultV(model.Tag, model.SchemaType()),
 	}
-
-	if model.InPath {
-		p.In = InPath
+	if model.SchemaType() == godantic.ArrayType {
+		p.Schema.Items = map[string]any{"type": model.ItemType()}
 	}
+	// 生成参数的枚举值
+	if es := godantic.SchemaEnum(model.Tag); len(es) > 0 {
+		otype := model.SchemaType()
+		if otype == godantic.ArrayType {
+			otype = model.ItemType()
+		}
+		for _, e := range es {
+			p.Schema.Enum = append(p.Schema.Enum, godantic.StringToOType(e, otype))
+		}
+	}
+	// 生成参数的校验约束
+	p.Schema.SetConstraints(godantic.SchemaConstraints(model.Tag, model.SchemaType()))
 
 	return p
 }
+
+// canonicalJson 按字段名排序重新序列化文档, 使相同的路由总是生成完全相同的文档, 便于比较不同版本间的差异;
+// 3.1 版本的文档同时将 exclusiveMinimum/exclusiveMaximum 由布尔值改为数字(JSON Schema 2020-12), 如:
+// {"minimum": 1, "exclusiveMinimum": true} -> {"exclusiveMinimum": 1}
+//
+//	@param	doc	[]byte	3.0 格式的文档
+//	@param	v31	bool	是否转换为 3.1 格式
+//	@return	[]byte 排序后的文档
+func canonicalJson(doc []byte, v31 bool) ([]byte, error) {
+	var root any
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber() // 保持数字的原始写法
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+	if v31 {
+		walkSchema31(root)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func walkSchema31(node any) {
+	switch n := node.(type) {
+	case map[string]any:
+		for exclusive, limit := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+			if flag, ok := n[exclusive].(bool); ok {
+				delete(n, exclusive)
+				if flag {
+					n[exclusive] = n[limit]
+					delete(n, limit)
+				}
+			}
+		}
+		for _, v := range n {
+			walkSchema31(v)
+		}
+	case []any:
+		for _, v := range n {
+			walkSchema31(v)
+		}
+	}
+}
+
+// JsonToYaml 将 json 格式的文档转换为 yaml 格式, 保持字段顺序和数字的原始写法
+//
+//	@param	doc	[]byte	json 格式的文档
+//	@return	[]byte yaml 格式的文档
+func JsonToYaml(doc []byte) ([]byte, error) {
+	// json 是 yaml 的子集, 解析为节点后改为块格式输出即可
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(doc, node); err != nil {
+		return nil, err
+	}
+	resetYamlStyle(node)
+
+	return yaml.Marshal(node)
+}
+
+// resetYamlStyle 清除 json 的流式格式和引号, 必要时字符串仍会被加上引号
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OpenApi 规范的 JSON Schema, 来源及版本见 schemas/README.md
+//
+//go:embed schemas/*.json
+var specSchemaFiles embed.FS
+
+// 各个文档版本对应的 JSON Schema 文件
+var specSchemaNames = map[string]string{
+	"3.0": "schemas/oas-3.0.json",
+	"3.1": "schemas/oas-3.1.json",
+}
+
+var (
+	specSchemas    = map[string]*jsonschema.Schema{}
+	specSchemaLock = &sync.Mutex{}
+)
+
+// SpecError 文档不符合 OpenApi 规范的错误, 每一条问题均以 json pointer 定位
+type SpecError struct {
+	Problems []string
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("openapi: %d problem(s): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Validate 依据 OpenApi 官方发布的 JSON Schema 检查文档是否符合其声明的版本(3.0.x 或 3.1.x)
+//
+//	@param	doc	[]byte	json格式的文档
+//	@return	error 不符合规范时为 *SpecError
+func Validate(doc []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var root any
+	if err := dec.Decode(&root); err != nil {
+		return &SpecError{Problems: []string{"#: " + err.Error()}}
+	}
+
+	m, _ := root.(map[string]any)
+	version, _ := m["openapi"].(string)
+	minor := ""
+	if parts := strings.SplitN(version, ".", 3); len(parts) == 3 {
+		minor = parts[0] + "." + parts[1]
+	}
+	if _, ok := specSchemaNames[minor]; !ok {
+		return &SpecError{Problems: []string{fmt.Sprintf("#/openapi: unsupported openapi version '%v'", m["openapi"])}}
+	}
+
+	schema, err := specSchema(minor)
+	if err != nil {
+		return err
+	}
+	if err = schema.Validate(root); err != nil {
+		ve, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return &SpecError{Problems: []string{"#: " + err.Error()}}
+		}
+		return &SpecError{Problems: specProblems(ve)}
+	}
+	return nil
+}
+
+// Validate 检查文档是否符合 OpenApi 规范
+func (o *OpenApi) Validate() error { return Validate(o.Schema()) }
+
+// specSchema 获取文档版本对应的 JSON Schema, 首次使用时编译
+func specSchema(minor string) (*jsonschema.Schema, error) {
+	specSchemaLock.Lock()
+	defer specSchemaLock.Unlock()
+
+	if schema, ok := specSchemas[minor]; ok {
+		return schema, nil
+	}
+
+	data, err := specSchemaFiles.ReadFile(specSchemaNames[minor])
+	if err != nil {
+		return nil, err
+	}
+	meta := struct {
+		Id      string `json:"$id"`
+		Draft04 string `json:"id"`
+	}{}
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	id := meta.Id
+	if id == "" {
+		id = meta.Draft04
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource(id, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	schema, err := compiler.Compile(id)
+	if err != nil {
+		return nil, err
+	}
+	specSchemas[minor] = schema
+	return schema, nil
+}
+
+// specProblems 将校验错误展开为以 json pointer 定位的问题列表, 仅保留最具体的错误
+func specProblems(ve *jsonschema.ValidationError) []string {
+	seen := map[string]struct{}{}
+	problems := make([]string, 0)
+
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		problem := "#" + e.InstanceLocation + ": " + e.Message
+		if _, ok := seen[problem]; !ok {
+			seen[problem] = struct{}{}
+			problems = append(problems, problem)
+		}
+	}
+	walk(ve)
+
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		problems []string // 问题的 json pointer 前缀
+	}{
+		{
+			name: "3.0",
+			doc:  `{"openapi": "3.0.3", "info": {"title": "t", "version": "1"}, "paths": {}}`,
+		},
+		{
+			name: "3.1 without paths",
+			doc:  `{"openapi": "3.1.0", "info": {"title": "t", "version": "1"}, "webhooks": {}}`,
+		},
+		{name: "invalid json", doc: `{`, problems: []string{"#: "}},
+		{name: "unsupported version", doc: `{"openapi": "2.0"}`, problems: []string{"#/openapi: "}},
+		{name: "missing version", doc: `{}`, problems: []string{"#/openapi: "}},
+		{
+			name:     "3.0 missing info",
+			doc:      `{"openapi": "3.0.3", "paths": {}}`,
+			problems: []string{"#: "},
+		},
+		{
+			name: "3.0 nullable tags",
+			doc: `{"openapi": "3.0.3", "info": {"title": "t", "version": "1"}, "paths": {
+				"/a/b": {"get": {"tags": null, "responses": {"200": {"description": "ok"}}}}
+			}}`,
+			problems: []string{"#/paths/~1a~1b/get/tags: "},
+		},
+		{
+			name: "3.1 response without description",
+			doc: `{"openapi": "3.1.0", "info": {"title": "t", "version": "1"}, "paths": {
+				"/a": {"get": {"responses": {"200": {}}}}
+			}}`,
+			problems: []string{"#/paths/~1a/get/responses/200: "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.doc))
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatalf("expected a valid document, got %v", err)
+				}
+				return
+			}
+
+			var se *SpecError
+			if !errors.As(err, &se) {
+				t.Fatalf("expected *SpecError, got %v", err)
+			}
+			for _, prefix := range tt.problems {
+				found := false
+				for _, p := range se.Problems {
+					found = found || strings.HasPrefix(p, prefix)
+				}
+				if !found {
+					t.Errorf("expected a problem at %q, got %v", prefix, se.Problems)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Chendemo12/flaskgo"
+	"mime/multipart"
 	"time"
 )
 
@@ -76,6 +77,21 @@ type SimpleForm struct {
 
 func (s *SimpleForm) SchemaDesc() string { return "简单的表单" }
 
+// FormQuery 查询参数
+type FormQuery struct {
+	flaskgo.QueryModel
+	Page int    `json:"page" description:"页码" default:"1" gte:"1"`
+	Sort string `json:"sort" description:"排序方式" oneof:"asc desc"`
+}
+
+// AvatarForm 上传头像
+type AvatarForm struct {
+	flaskgo.FileModel
+	Image  *multipart.FileHeader   `json:"image" description:"头像" max_size:"1048576" mime:"image/*" validate:"required"`
+	Photos []*multipart.FileHeader `json:"photos" description:"相册" mime:"image/png image/jpeg"`
+	UserId int                     `json:"user_id" description:"用户ID" validate:"required" gte:"1"`
+}
+
 type Step struct {
 	Click string `json:"click"`
 }
@@ -137,6 +153,11 @@ func getExampleForm(s *flaskgo.Context) *flaskgo.Response {
 	})
 }
 
+func uploadAvatar(s *flaskgo.Context) *flaskgo.Response {
+	form := s.RequestBody.(*AvatarForm)
+	return s.OKResponse(form.Image.Filename)
+}
+
 func makeRouter() *flaskgo.Router {
 	router := flaskgo.APIRouter("/api/device", []string{"Tunnel"})
 	{
@@ -147,7 +168,9 @@ func makeRouter() *flaskgo.Router {
 			getSimpleFrom,
 		)
 
-		router.GET("/form/:name", &ExampleForm{}, "获得一个随机表单", getExampleForm)
+		router.GET("/form/:name", &ExampleForm{}, "获得一个随机表单", getExampleForm, &FormQuery{})
+
+		router.POST("/avatar", &AvatarForm{}, flaskgo.String, "上传头像", uploadAvatar)
 
 		router.POST("/tunnel/:no", &TunnelWorkParams{}, flaskgo.Int, "设置通道工作参数", makeTunnelWork).
 			SetDescription("设置通道的工作参数，表单内部的`tunnel_no`必须与路径参数保持一致")
@@ -198,6 +221,16 @@ func ExampleFlaskGo_App() {
 	app.Run(conf.HTTP.Host, conf.HTTP.Port) // 阻塞运行
 }
 
+// ExampleFlaskGo_OpenAPIMain 无需启动服务, 将文档导出到文件, 通常作为项目中独立的 main 包:
+//
+//	go run ./cmd/openapi -o docs/openapi.yaml -openapi 3.1.0
+func ExampleFlaskGo_OpenAPIMain() {
+	app := flaskgo.NewFlaskGo("FlaskGo Example", "0.2.1", false, nil)
+	app.IncludeRouter(makeRouter())
+
+	flaskgo.OpenAPIMain(app) // 解析 -o 和 -openapi 参数并写入文件
+}
+
 // -----------------------------------------------------------------
 
 func main() {
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Chendemo12/flaskgo"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// OpenApi 规范的 JSON Schema, 与 flaskgo.ValidateOpenAPI 嵌入的文件相同, 见 internal/openapi/schemas/README.md
+var specSchemas = map[string]string{
+	flaskgo.OpenApiVersion303: "../internal/openapi/schemas/oas-3.0.json",
+	flaskgo.OpenApiVersion310: "../internal/openapi/schemas/oas-3.1.json",
+}
+
+type SecureQuery struct {
+	flaskgo.QueryModel
+	Page int `json:"page" gte:"1" default:"1"`
+}
+
+type SecureHeader struct {
+	flaskgo.HeaderModel
+	DeviceId string `json:"X-Device-Id" validate:"required"`
+}
+
+type SecureCookie struct {
+	flaskgo.CookieModel
+	Session string `json:"session"`
+}
+
+// makeSecureRouter 覆盖认证依赖项、请求头参数和cookie参数的路由组
+func makeSecureRouter() *flaskgo.Router {
+	ok := func(s *flaskgo.Context) *flaskgo.Response { return s.OKResponse("ok") }
+
+	router := flaskgo.APIRouter("/api/secure", []string{"Secure"})
+	router.GET("/key", flaskgo.String, "API密钥认证", ok, &SecureQuery{}, &SecureHeader{}, &SecureCookie{}).
+		AddSecurity(flaskgo.APIKeyHeader("X-Token", nil)).
+		SetScopes("read")
+	router.GET("/bearer", flaskgo.String, "Bearer认证", ok).
+		AddSecurity(flaskgo.HTTPBearer(nil))
+	router.GET("/oauth2", flaskgo.String, "OAuth2认证", ok).
+		AddSecurity(flaskgo.OAuth2PasswordBearer("/token", map[string]string{"read": "读取"}, nil)).
+		SetScopes("read")
+	return router
+}
+
+func newExampleApp(version string) *flaskgo.FlaskGo {
+	app := flaskgo.NewFlaskGo("FlaskGo Example", "0.2.1", false, nil)
+	app.SetOpenApiVersion(version)
+	app.IncludeRouter(makeRouter()).IncludeRouter(makeSecureRouter())
+	return app
+}
+
+func compileSpecSchema(t *testing.T, version string) *jsonschema.Schema {
+	t.Helper()
+	data, err := os.ReadFile(specSchemas[version])
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	var doc map[string]any
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	id, _ := doc["$id"].(string)
+	if id == "" {
+		id, _ = doc["id"].(string) // draft-04
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource(id, bytes.NewReader(data)); err != nil {
+		t.Fatalf("add schema: %v", err)
+	}
+	schema, err := compiler.Compile(id)
+	if err != nil {
+		t.Fatalf("compile schema: %v", err)
+	}
+	return schema
+}
+
+func decodeDocument(t *testing.T, bs []byte) any {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	return v
+}
+
+func TestOpenAPIConformsToSpecSchema(t *testing.T) {
+	for _, version := range []string{flaskgo.OpenApiVersion303, flaskgo.OpenApiVersion310} {
+		t.Run(version, func(t *testing.T) {
+			schema := compileSpecSchema(t, version)
+
+			bs, err := newExampleApp(version).OpenAPI()
+			if err != nil {
+				t.Fatalf("generate openapi: %v", err)
+			}
+			doc := decodeDocument(t, bs)
+			if v := doc.(map[string]any)["openapi"]; v != version {
+				t.Fatalf("expected openapi %s, got %v", version, v)
+			}
+			if err = schema.Validate(doc); err != nil {
+				t.Errorf("document does not conform to the OpenApi %s schema: %#v", version, err)
+			}
+		})
+	}
+}
+
+// TestSpecSchemaRejectsInvalidDocument 确保规范的 JSON Schema 能够发现不符合规范的文档, 而非全部通过
+func TestSpecSchemaRejectsInvalidDocument(t *testing.T) {
+	breakers := map[string]func(doc map[string]any){
+		"missing info":  func(doc map[string]any) { delete(doc, "info") },
+		"unknown field": func(doc map[string]any) { doc["unknown"] = true },
+		"optional path parameter": func(doc map[string]any) {
+			op := doc["paths"].(map[string]any)["/api/device/form/{name}"].(map[string]any)["get"].(map[string]any)
+			for _, p := range op["parameters"].([]any) {
+				if param := p.(map[string]any); param["in"] == "path" {
+					param["required"] = false
+				}
+			}
+		},
+		"response without description": func(doc map[string]any) {
+			op := doc["paths"].(map[string]any)["/api/secure/bearer"].(map[string]any)["get"].(map[string]any)
+			delete(op["responses"].(map[string]any)["200"].(map[string]any), "description")
+		},
+	}
+
+	for _, version := range []string{flaskgo.OpenApiVersion303, flaskgo.OpenApiVersion310} {
+		schema := compileSpecSchema(t, version)
+		bs, _ := newExampleApp(version).OpenAPI()
+
+		for name, breaker := range breakers {
+			t.Run(version+"/"+strings.ReplaceAll(name, " ", "_"), func(t *testing.T) {
+				doc := decodeDocument(t, bs).(map[string]any)
+				breaker(doc)
+				if err := schema.Validate(doc); err == nil {
+					t.Errorf("expected the OpenApi %s schema to reject the document", version)
+				}
+				broken, _ := json.Marshal(doc)
+				if err := flaskgo.ValidateOpenAPI(broken); err == nil {
+					t.Errorf("expected ValidateOpenAPI to reject the document")
+				}
+			})
+		}
+	}
+}